@@ -0,0 +1,86 @@
+package env
+
+import (
+	"path"
+	"strings"
+)
+
+// An entry in a file that appears to hold a real secret, see Audit.
+type Finding struct {
+	Line int
+	Key  string
+}
+
+// Values, or parts of values, that mark a placeholder rather than a secret.
+var placeholders = []string{
+	"changeme", "change-me", "change_me", "changeit", "example", "placeholder",
+	"replace", "your", "todo", "dummy", "sample", "fixme", "xxx", "***",
+}
+
+// Scan a .env file for entries that look like secrets committed by mistake,
+// such as before checking it in. An entry is reported if its key matches one
+// of the given patterns (exact names or globs as understood by path.Match,
+// with keys containing SECRET, PASSWORD, TOKEN or KEY if none are given) and
+// its value looks like a real credential: at least 8 characters mixing
+// letters with digits or symbols, and neither a variable reference nor a
+// placeholder such as "changeme" or "<your-token>". Findings report the line
+// and key but never the value. The file itself is not loaded.
+func Audit(name string, patterns ...string) (findings []Finding, err error) {
+	if len(patterns) == 0 {
+		patterns = defaultRedactKeys
+	}
+
+	var line int
+
+	set := func(key, value string) error {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, key); ok {
+				if credential(value) {
+					findings = append(findings, Finding{Line: line, Key: key})
+				}
+				break
+			}
+		}
+		return nil
+	}
+
+	lookup := func(string) (string, bool) { return EmptyString, false }
+
+	err = loader{set: set, lookup: lookup, opts: LoadOptions{NoExpand: true}, line: &line}.file(name)
+
+	return
+}
+
+// Determine if a value looks like a real credential rather than a
+// placeholder, see Audit.
+func credential(value string) bool {
+	if len(value) < 8 || strings.Contains(value, "$") {
+		return false
+	}
+
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return false
+		}
+	}
+
+	if strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">") {
+		return false
+	}
+
+	if strings.Trim(value, value[:1]) == EmptyString {
+		return false
+	}
+
+	var letters, others bool
+	for _, r := range value {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+			letters = true
+		} else {
+			others = true
+		}
+	}
+
+	return letters && others
+}
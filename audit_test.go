@@ -0,0 +1,55 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	name := writeFile(t, ".env", `# Credentials
+API_TOKEN=ghp_4f9Kd82jXq0LmZ71
+DB_PASSWORD=changeme
+
+export AWS_SECRET_ACCESS_KEY="wJalrXUtnFEMI/K7MDENG"
+SIGNING_KEY=<your-signing-key>
+SESSION_SECRET=${VAULT_SESSION_SECRET}
+ADMIN_PASSWORD=xxxxxxxxxxxx
+STRIPE_KEY=********
+PRIVATE_KEY="-----BEGIN KEY-----
+MIIEvQIBADANBgkqhkiG9w0B
+-----END KEY-----"
+SHORT_TOKEN=a1b2c3
+WORDS_SECRET=onlyletters
+DIGITS_TOKEN=1234567890
+HOST=db.internal:5432
+REFRESH_TOKEN='p@ssw0rd-2024!x'
+`)
+
+	findings, err := Audit(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Finding{
+		{Line: 2, Key: "API_TOKEN"},
+		{Line: 5, Key: "AWS_SECRET_ACCESS_KEY"},
+		{Line: 10, Key: "PRIVATE_KEY"},
+		{Line: 17, Key: "REFRESH_TOKEN"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Audit = %+v, want %+v", findings, want)
+	}
+}
+
+func TestAuditPatterns(t *testing.T) {
+	name := writeFile(t, ".env", "API_TOKEN=ghp_4f9Kd82jXq0LmZ71\nDSN=postgres://u:S3cr3tPw@db/app\n")
+
+	findings, err := Audit(name, "DSN")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []Finding{{Line: 2, Key: "DSN"}}; !reflect.DeepEqual(findings, want) {
+		t.Errorf("Audit = %+v, want %+v", findings, want)
+	}
+}
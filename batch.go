@@ -0,0 +1,145 @@
+package env
+
+// A set of changes to an environment that are applied together by Commit,
+// created by NewBatch.
+type Batch struct {
+	env   *Env
+	ops   []batchOp
+	prior state
+}
+
+// A staged change, unsetting key if unset is true.
+type batchOp struct {
+	key, value string
+	unset      bool
+}
+
+// Create a batch of changes to the environment.
+func NewBatch() *Batch {
+	return std.NewBatch()
+}
+
+// Create a batch of changes to this environment.
+func (e *Env) NewBatch() *Batch {
+	return &Batch{env: e}
+}
+
+// Stage setting an environment variable.
+func (b *Batch) Set(key, value string) {
+	b.ops = append(b.ops, batchOp{key: key, value: value})
+}
+
+// Stage unsetting an environment variable.
+func (b *Batch) Unset(key string) {
+	b.ops = append(b.ops, batchOp{key: key, unset: true})
+}
+
+// Apply every staged change as a single atomic operation. If any change
+// fails, those already applied are undone and the error returned. The values
+// replaced are kept so that Rollback can later undo the commit.
+func (b *Batch) Commit() (err error) {
+	e := b.env
+
+	keys := make([]string, 0, len(b.ops))
+	for _, op := range b.ops {
+		keys = append(keys, op.key)
+	}
+
+	e.mu.Lock()
+	prior := e.capture(keys)
+	if err = e.apply(b.ops); err != nil {
+		e.restore(prior)
+	}
+	changes := e.changes(prior)
+	e.mu.Unlock()
+
+	if err == nil {
+		b.ops, b.prior = nil, prior
+	}
+
+	changes.notify(e)
+
+	return
+}
+
+// Apply staged changes in order, stopping at the first error. Callers must
+// hold e.mu for writing.
+func (e *Env) apply(ops []batchOp) (err error) {
+	if e.frozen {
+		return ErrFrozen
+	}
+
+	for _, op := range ops {
+		if op.unset {
+			err = e.unsetenv(op.key)
+		} else {
+			err = e.setenv(op.key, op.value)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Discard any staged changes and, if the batch has been committed, return
+// the variables it changed to their values before the commit.
+func (b *Batch) Rollback() (err error) {
+	e := b.env
+	b.ops = nil
+
+	if b.prior == nil {
+		return
+	}
+
+	e.mu.Lock()
+	current := e.capture(keysOf(b.prior))
+	err = e.restore(b.prior)
+	changes := e.changes(current)
+	e.mu.Unlock()
+
+	b.prior = nil
+	changes.notify(e)
+
+	return
+}
+
+// A list of variables whose values changed, as old and new values.
+type changes [][3]string
+
+// Compare a recorded state to the current values of its keys. Callers must
+// hold e.mu.
+func (e *Env) changes(s state) (c changes) {
+	for k, v := range s {
+		var old string
+		if v != nil {
+			old = *v
+		}
+
+		if cur, _ := e.lookupvar(k); cur != old {
+			c = append(c, [3]string{k, old, cur})
+		}
+	}
+
+	return
+}
+
+// Invoke OnChange callbacks for each change. Must not be called with e.mu
+// held.
+func (c changes) notify(e *Env) {
+	for _, ch := range c {
+		e.notify(ch[0], ch[1], ch[2])
+	}
+}
+
+// List the keys of a recorded state.
+func keysOf(s state) []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,81 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+// Report any variables in e that differ from want, where a nil value in want
+// means the variable should be unset.
+func checkVars(t *testing.T, e *Env, want map[string]*string) {
+	t.Helper()
+
+	for k, v := range want {
+		got, ok := e.Lookup(k)
+		switch {
+		case v == nil && ok:
+			t.Errorf("%s = %q, want unset", k, got)
+		case v != nil && (!ok || got != *v):
+			t.Errorf("%s = %q, want %q", k, got, *v)
+		}
+	}
+}
+
+// Return a pointer to s.
+func str(s string) *string {
+	return &s
+}
+
+func TestBatchCommitRollback(t *testing.T) {
+	e := New(map[string]string{"A": "1", "B": "2"})
+
+	b := e.NewBatch()
+	b.Set("A", "one")
+	b.Unset("B")
+	b.Set("C", "three")
+	checkVars(t, e, map[string]*string{"A": str("1"), "B": str("2"), "C": nil})
+
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkVars(t, e, map[string]*string{"A": str("one"), "B": nil, "C": str("three")})
+
+	if err := b.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	checkVars(t, e, map[string]*string{"A": str("1"), "B": str("2"), "C": nil})
+}
+
+func TestBatchCommitFailure(t *testing.T) {
+	e := New(map[string]string{"A": "1", "B": "2"})
+
+	b := e.NewBatch()
+	b.Set("A", "one")
+	b.Unset("B")
+	b.Set("C=D", "bad")
+	b.Set("E", "five")
+	if err := b.Commit(); err == nil {
+		t.Fatal("Commit succeeded despite an invalid key")
+	}
+	checkVars(t, e, map[string]*string{"A": str("1"), "B": str("2"), "E": nil})
+
+	// A failed commit leaves nothing to roll back.
+	e.Set("A", "later")
+	if err := b.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	checkVars(t, e, map[string]*string{"A": str("later")})
+}
+
+func TestBatchCommitFrozen(t *testing.T) {
+	e := New(map[string]string{"A": "1"})
+	e.Freeze()
+
+	b := e.NewBatch()
+	b.Set("A", "one")
+	b.Set("B", "two")
+	if err := b.Commit(); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("Commit error = %v, want ErrFrozen", err)
+	}
+	checkVars(t, e, map[string]*string{"A": str("1"), "B": nil})
+}
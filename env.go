@@ -1,49 +1,437 @@
 package env
 
 import (
-	"bufio"
+	"errors"
+	"fmt"
 	"os"
+	"path"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
 	DefaultFile = ".env"
 	EmptyString = ""
+
+	// The variable naming the current environment (such as "development" or
+	// "production") used by LoadForEnv.
+	DefaultEnvironmentKey = "APP_ENV"
 )
 
+// A set of environment variables. The package level functions operate on the
+// process environment, while an Env created with New holds its own variables
+// in isolation, such as for tests or per-tenant configuration.
+type Env struct {
+	mu      sync.RWMutex
+	vars    map[string]string
+	process bool
+	frozen  bool
+	sources []Source
+	shadow  map[string]string
+	logger  func(key, source string)
+
+	observersMu sync.RWMutex
+	observers   map[string][]func(old, new string)
+}
+
+// The process environment, used by the package level functions.
+var std = &Env{process: true}
+
+// Create an isolated environment holding a copy of the given variables, which
+// may be nil. The zero value of Env is an empty isolated environment.
+func New(vals map[string]string) *Env {
+	e := &Env{vars: make(map[string]string, len(vals))}
+	for k, v := range vals {
+		e.vars[k] = v
+	}
+
+	return e
+}
+
 // Get an environment variable, returns "" (empty string) if unset.
 func Get(key string) string {
-	return os.Getenv(key)
+	return std.Get(key)
+}
+
+// Get an environment variable, returns "" (empty string) if unset.
+func (e *Env) Get(key string) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.getenv(key)
+}
+
+// Look up an environment variable, reporting whether it is set (even if to ""
+// (empty string)).
+func Lookup(key string) (string, bool) {
+	return std.Lookup(key)
+}
+
+// Look up an environment variable, reporting whether it is set (even if to ""
+// (empty string)).
+func (e *Env) Lookup(key string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.lookupenv(key)
+}
+
+// Get an environment variable with any $VAR or ${VAR} references in its value
+// expanded against the current environment, including ${VAR:-default} and
+// ${VAR:+alternate} forms as described in LoadFile. Undefined references
+// expand to "" (empty string).
+func GetExpanded(key string) string {
+	return std.GetExpanded(key)
+}
+
+// Get an environment variable with any $VAR or ${VAR} references in its value
+// expanded against the current environment, including ${VAR:-default} and
+// ${VAR:+alternate} forms as described in LoadFile. Undefined references
+// expand to "" (empty string).
+func (e *Env) GetExpanded(key string) string {
+	v, _ := loader{lookup: e.Lookup}.expand(e.Get(key), false)
+
+	return v
+}
+
+// Get an environment variable by a case-insensitive match of its name, returns
+// "" (empty string) if unset. An exact match is preferred, otherwise when
+// several names differ only in case the one that sorts first wins. This scans
+// the whole environment, so prefer Get where the case is known.
+func GetInsensitive(key string) string {
+	return std.GetInsensitive(key)
+}
+
+// Get a variable by a case-insensitive match of its name, see GetInsensitive.
+func (e *Env) GetInsensitive(key string) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if v, ok := e.lookupenv(key); ok {
+		return v
+	}
+
+	var match string
+	var found bool
+	for k := range e.environ() {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+
+	if !found {
+		return EmptyString
+	}
+
+	return e.getenv(match)
 }
 
-// Get a key or panic
+// Get a key or panic with a *MissingError if it is unset
 func MustGet(key string) string {
-	v := Get(key)
+	return std.MustGet(key)
+}
+
+// Get a key or panic with a *MissingError if it is unset
+func (e *Env) MustGet(key string) string {
+	v := e.Get(key)
 	if v == "" {
-		panic("missing environment variable " + key)
+		panic(&MissingError{Key: key})
 	}
 
 	return v
 }
 
+// Get an environment variable, returning a *MissingError if it is unset.
+func GetRequired(key string) (v string, err error) {
+	return std.GetRequired(key)
+}
+
+// Get an environment variable, returning a *MissingError if it is unset.
+func (e *Env) GetRequired(key string) (v string, err error) {
+	if v = e.Get(key); v == EmptyString {
+		err = &MissingError{Key: key}
+	}
+
+	return
+}
+
+// Get a secret from an environment variable or, if it is unset, from the file
+// named by the variable with a "_FILE" suffix, as Docker and Kubernetes
+// secrets are commonly provided. So GetSecret("DB_PASSWORD") returns the value
+// of DB_PASSWORD if set, otherwise the contents of the file at
+// DB_PASSWORD_FILE with surrounding whitespace trimmed. Returns an empty string
+// and no error if neither is set.
+func GetSecret(key string) (string, error) {
+	return std.GetSecret(key)
+}
+
+// Get a secret from an environment variable or a file, see GetSecret.
+func (e *Env) GetSecret(key string) (string, error) {
+	if v := e.Get(key); v != EmptyString {
+		return v, nil
+	}
+
+	name := e.Get(key + "_FILE")
+	if name == EmptyString {
+		return EmptyString, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return EmptyString, fmt.Errorf("env: reading %s_FILE: %w", key, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Ensure the given environment variables are all set, returning an error
+// naming each one that is missing.
+func Require(keys ...string) error {
+	return std.Require(keys...)
+}
+
+// Ensure the given environment variables are all set, returning an error
+// naming each one that is missing.
+func (e *Env) Require(keys ...string) error {
+	var errs []error
+
+	for _, key := range keys {
+		if _, err := e.GetRequired(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Ensure the given environment variables are all set, returning a
+// *RequiredError naming every one that is missing in a single message such as
+// "env: missing required variables: DB_HOST, DB_USER".
+func RequireAll(keys ...string) error {
+	return std.RequireAll(keys...)
+}
+
+// Ensure the given environment variables are all set, see RequireAll.
+func (e *Env) RequireAll(keys ...string) error {
+	var missing []string
+
+	for _, key := range keys {
+		if !e.IsSet(key) {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &RequiredError{Keys: missing}
+	}
+
+	return nil
+}
+
 // Get an environment variable if it exists, otherwise return an alternate value.
 func GetOr(key string, alt string) (result string) {
-	if result = Get(key); result == EmptyString {
+	return std.GetOr(key, alt)
+}
+
+// Get an environment variable if it exists, otherwise return an alternate value.
+func (e *Env) GetOr(key string, alt string) (result string) {
+	if result = e.Get(key); result == EmptyString {
+		result = alt
+	}
+
+	return
+}
+
+// Get an environment variable with surrounding whitespace trimmed, returning
+// an alternate value if the result is empty.
+func GetOrTrim(key, alt string) string {
+	return std.GetOrTrim(key, alt)
+}
+
+// Get an environment variable with surrounding whitespace trimmed, returning
+// an alternate value if the result is empty.
+func (e *Env) GetOrTrim(key, alt string) (result string) {
+	if result = strings.TrimSpace(e.Get(key)); result == EmptyString {
 		result = alt
 	}
 
 	return
 }
 
-// Sets an environment variable unconditionally.
+// Get an environment variable if it exists, otherwise return the result of
+// calling fn. The function is only called when the variable is unset.
+func GetOrFunc(key string, fn func() string) (result string) {
+	return std.GetOrFunc(key, fn)
+}
+
+// Get an environment variable if it exists, otherwise return the result of
+// calling fn. The function is only called when the variable is unset.
+func (e *Env) GetOrFunc(key string, fn func() string) (result string) {
+	if result = e.Get(key); result == EmptyString {
+		result = fn()
+	}
+
+	return
+}
+
+// Get the value of the first of several environment variables that is set,
+// in the order given, or "" (empty string) if none are. This suits variables
+// that have been renamed, as in GetFirst("NEW_DB_URL", "DATABASE_URL").
+func GetFirst(keys ...string) string {
+	return std.GetFirst(keys...)
+}
+
+// Get the value of the first of several environment variables that is set,
+// see GetFirst.
+func (e *Env) GetFirst(keys ...string) string {
+	return e.GetFirstOr(EmptyString, keys...)
+}
+
+// Get the value of the first of several environment variables that is set,
+// returning an alternate value if none are. See GetFirst.
+func GetFirstOr(alt string, keys ...string) string {
+	return std.GetFirstOr(alt, keys...)
+}
+
+// Get the value of the first of several environment variables that is set,
+// returning an alternate value if none are. See GetFirst.
+func (e *Env) GetFirstOr(alt string, keys ...string) string {
+	for _, key := range keys {
+		if v := e.Get(key); v != EmptyString {
+			return v
+		}
+	}
+
+	return alt
+}
+
+// Get an environment variable named by a "KEY:default" spec, returning the
+// default (everything after the first colon) if it is unset or empty. So
+// "PORT:8080" reads PORT, falling back to 8080. Colons after the first are
+// part of the default, as in "URL:http://localhost", and may also be written
+// as "\:". A spec without a colon has an empty default.
+func GetDefault(spec string) string {
+	return std.GetDefault(spec)
+}
+
+// Get an environment variable named by a "KEY:default" spec, see GetDefault.
+func (e *Env) GetDefault(spec string) string {
+	key, alt, _ := strings.Cut(spec, ":")
+
+	return e.GetOr(key, strings.ReplaceAll(alt, `\:`, ":"))
+}
+
+// Sets an environment variable unconditionally, notifying any OnChange
+// callbacks if its value changed. Returns an error if the key is empty or
+// contains "=" or a NUL byte.
 func Set(key, value string) error {
-	return os.Setenv(key, value)
+	return std.Set(key, value)
+}
+
+// Sets an environment variable unconditionally, notifying any OnChange
+// callbacks if its value changed. Returns an error if the key is empty or
+// contains "=" or a NUL byte.
+func (e *Env) Set(key, value string) error {
+	_, err := e.Swap(key, value)
+	return err
+}
+
+// Sets an environment variable and returns its previous value as a single
+// atomic operation.
+func Swap(key, value string) (old string, err error) {
+	return std.Swap(key, value)
+}
+
+// Sets an environment variable and returns its previous value as a single
+// atomic operation.
+func (e *Env) Swap(key, value string) (old string, err error) {
+	e.mu.Lock()
+	old = e.getenv(key)
+	err = e.setenv(key, value)
+	e.mu.Unlock()
+
+	if err == nil && old != value {
+		e.notify(key, old, value)
+	}
+
+	return
+}
+
+// Sets an environment variable only if its current value is old, as a single
+// atomic operation. Reports whether the value was set.
+func CompareAndSwap(key, old, new string) (swapped bool, err error) {
+	return std.CompareAndSwap(key, old, new)
+}
+
+// Sets an environment variable only if its current value is old, as a single
+// atomic operation. Reports whether the value was set.
+func (e *Env) CompareAndSwap(key, old, new string) (swapped bool, err error) {
+	e.mu.Lock()
+	if e.frozen {
+		err = ErrFrozen
+	} else if e.getenv(key) == old {
+		err = e.setenv(key, new)
+		swapped = err == nil
+	}
+	e.mu.Unlock()
+
+	if swapped && old != new {
+		e.notify(key, old, new)
+	}
+
+	return
+}
+
+// Performs Set on a map of key/value pairs, stopping at the first error.
+func SetAll(vals map[string]string) (err error) {
+	return std.SetAll(vals)
+}
+
+// Performs Set on a map of key/value pairs, stopping at the first error.
+func (e *Env) SetAll(vals map[string]string) (err error) {
+	for k, v := range vals {
+		if err = e.Set(k, v); err != nil {
+			return
+		}
+	}
+
+	return
 }
 
-// Sets an environment variable only if it is not already set.
-func SetDefault(key, value string) (err error) {
-	if !IsSet(key) {
-		err = Set(key, value)
+// Sets an environment variable only if it is not already set (see IsSet), as
+// a single atomic operation.
+func SetDefault(key, value string) error {
+	return std.SetDefault(key, value)
+}
+
+// Sets an environment variable only if it is not already set (see IsSet), as
+// a single atomic operation.
+func (e *Env) SetDefault(key, value string) error {
+	_, err := e.SetDefaultOK(key, value)
+	return err
+}
+
+// Sets an environment variable as SetDefault does, reporting whether the
+// value was applied.
+func SetDefaultOK(key, value string) (set bool, err error) {
+	return std.SetDefaultOK(key, value)
+}
+
+// Sets an environment variable as SetDefault does, reporting whether the
+// value was applied.
+func (e *Env) SetDefaultOK(key, value string) (set bool, err error) {
+	e.mu.Lock()
+	if e.frozen {
+		err = ErrFrozen
+	} else if e.getenv(key) == EmptyString {
+		err = e.setenv(key, value)
+		set = err == nil
+	}
+	e.mu.Unlock()
+
+	if set && value != EmptyString {
+		e.notify(key, EmptyString, value)
 	}
 
 	return
@@ -51,8 +439,13 @@ func SetDefault(key, value string) (err error) {
 
 // Performs SetDefault on a map of key/value pairs.
 func SetDefaults(vals map[string]string) (err error) {
+	return std.SetDefaults(vals)
+}
+
+// Performs SetDefault on a map of key/value pairs.
+func (e *Env) SetDefaults(vals map[string]string) (err error) {
 	for k, v := range vals {
-		if err = SetDefault(k, v); err != nil {
+		if err = e.SetDefault(k, v); err != nil {
 			return
 		}
 	}
@@ -60,40 +453,353 @@ func SetDefaults(vals map[string]string) (err error) {
 	return
 }
 
-// Determine if an environment variable is set or not.
+// Apply a map of key/value pairs to the environment, overwriting variables
+// that are already set if overwrite is true (as SetAll does) or preserving
+// them if not (as SetDefaults does). Stops at the first error.
+func Merge(other map[string]string, overwrite bool) error {
+	return std.Merge(other, overwrite)
+}
+
+// Apply a map of key/value pairs to the environment, overwriting variables
+// that are already set if overwrite is true (as SetAll does) or preserving
+// them if not (as SetDefaults does). Stops at the first error.
+func (e *Env) Merge(other map[string]string, overwrite bool) error {
+	if overwrite {
+		return e.SetAll(other)
+	}
+
+	return e.SetDefaults(other)
+}
+
+// Determine if an environment variable exists, even if its value is "" (empty
+// string).
+func Has(key string) bool {
+	return std.Has(key)
+}
+
+// Determine if an environment variable exists, even if its value is "" (empty
+// string).
+func (e *Env) Has(key string) bool {
+	_, ok := e.Lookup(key)
+	return ok
+}
+
+// Determine if an environment variable is set to a non-empty value.
 func IsSet(key string) bool {
-	return (Get(key) != EmptyString)
+	return std.IsSet(key)
+}
+
+// Determine if an environment variable is set to a non-empty value.
+func (e *Env) IsSet(key string) bool {
+	return (e.Get(key) != EmptyString)
+}
+
+// Unset an environment variable, removing it from the environment entirely
+// and notifying any OnChange callbacks if it had a value.
+func Unset(key string) (err error) {
+	return std.Unset(key)
+}
+
+// Unset an environment variable, removing it from the environment entirely
+// and notifying any OnChange callbacks if it had a value.
+func (e *Env) Unset(key string) (err error) {
+	e.mu.Lock()
+	old := e.getenv(key)
+	err = e.unsetenv(key)
+	e.mu.Unlock()
+
+	if err == nil && old != EmptyString {
+		e.notify(key, old, EmptyString)
+	}
+
+	return
+}
+
+// Remove every environment variable. Returns ErrFrozen, leaving the
+// environment unchanged, if it is frozen.
+func Clear() error {
+	return std.Clear()
 }
 
-// Unset an environment variable
-func Unset(key string) error {
-	return Set(key, EmptyString)
+// Remove every environment variable, see Clear.
+func (e *Env) Clear() (err error) {
+	e.mu.Lock()
+	vals := e.environ()
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+
+	prior := e.capture(keys)
+	err = e.clearenv()
+	changes := e.changes(prior)
+	e.mu.Unlock()
+
+	changes.notify(e)
+
+	return
+}
+
+// Remove every environment variable beginning with prefix as UnsetPrefix
+// does, returning its error, such as ErrFrozen if the environment is frozen.
+func ClearPrefix(prefix string) error {
+	return std.ClearPrefix(prefix)
 }
 
-// Load the environment variables from the ".env" file.
-func Load() error {
-	return LoadFile(DefaultFile)
+// Remove every environment variable beginning with prefix, see ClearPrefix.
+func (e *Env) ClearPrefix(prefix string) error {
+	return e.UnsetPrefix(prefix)
+}
+
+// Unset every environment variable beginning with prefix, stopping at the
+// first error.
+func UnsetPrefix(prefix string) error {
+	return std.UnsetPrefix(prefix)
+}
+
+// Unset every environment variable beginning with prefix, stopping at the
+// first error.
+func (e *Env) UnsetPrefix(prefix string) error {
+	return e.unsetMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// Unset every environment variable whose name matches a glob pattern as
+// understood by path.Match, such as "APP_PLUGIN_*", stopping at the first
+// error. Returns path.ErrBadPattern if the pattern is malformed.
+func UnsetMatching(pattern string) error {
+	return std.UnsetMatching(pattern)
+}
+
+// Unset every environment variable whose name matches a glob pattern as
+// understood by path.Match, such as "APP_PLUGIN_*", stopping at the first
+// error. Returns path.ErrBadPattern if the pattern is malformed.
+func (e *Env) UnsetMatching(pattern string) error {
+	if _, err := path.Match(pattern, EmptyString); err != nil {
+		return err
+	}
+
+	return e.unsetMatching(func(key string) bool {
+		ok, _ := path.Match(pattern, key)
+		return ok
+	})
 }
 
-// Load environment variables from a given filename.
-func LoadFile(name string) (err error) {
-	var file *os.File
-	var scanner *bufio.Scanner
+// Unset every environment variable for which match reports true, notifying
+// OnChange callbacks for those that had a value.
+func (e *Env) unsetMatching(match func(key string) bool) (err error) {
+	var removed []string
+	var olds []string
 
-	if file, err = os.Open(name); err != nil {
-		return
+	e.mu.Lock()
+	if e.frozen {
+		e.mu.Unlock()
+		return ErrFrozen
 	}
-	defer file.Close()
 
-	scanner = bufio.NewScanner(file)
-	for scanner.Scan() {
-		parts := strings.SplitN(scanner.Text(), "=", 2)
-		if len(parts) == 2 {
-			if err = Set(parts[0], parts[1]); err != nil {
-				return
-			}
+	for k, v := range e.environ() {
+		if !match(k) {
+			continue
+		}
+
+		if err = e.unsetenv(k); err != nil {
+			break
+		}
+
+		if v != EmptyString {
+			removed, olds = append(removed, k), append(olds, v)
 		}
 	}
+	e.mu.Unlock()
+
+	for i, k := range removed {
+		e.notify(k, olds[i], EmptyString)
+	}
 
 	return
 }
+
+// List the names of all set environment variables beginning with prefix,
+// sorted. An empty prefix lists every variable.
+func Keys(prefix string) []string {
+	return std.Keys(prefix)
+}
+
+// List the names of all set environment variables beginning with prefix,
+// sorted. An empty prefix lists every variable.
+func (e *Env) Keys(prefix string) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	keys := []string{}
+	for k := range e.environ() {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Get all environment variables beginning with prefix, keyed by their names
+// with the prefix removed. A variable named exactly prefix is excluded.
+func GetMap(prefix string) map[string]string {
+	return std.GetMap(prefix)
+}
+
+// Get all environment variables beginning with prefix, keyed by their names
+// with the prefix removed. A variable named exactly prefix is excluded.
+func (e *Env) GetMap(prefix string) map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	vals := map[string]string{}
+	for k, v := range e.environ() {
+		if strings.HasPrefix(k, prefix) && len(k) > len(prefix) {
+			vals[k[len(prefix):]] = v
+		}
+	}
+
+	return vals
+}
+
+// The methods below access the underlying variables and must be called with
+// e.mu held.
+
+func (e *Env) getenv(key string) string {
+	v, _ := e.lookupenv(key)
+	return v
+}
+
+func (e *Env) lookupenv(key string) (string, bool) {
+	for _, s := range e.sources {
+		if v, ok := s.Lookup(key); ok {
+			return v, true
+		}
+	}
+
+	return e.lookupvar(key)
+}
+
+// Look up a variable held by the environment itself, ignoring any sources.
+func (e *Env) lookupvar(key string) (string, bool) {
+	if e.process {
+		return os.LookupEnv(key)
+	}
+
+	v, ok := e.vars[key]
+	return v, ok
+}
+
+func (e *Env) setenv(key, value string) error {
+	if e.frozen {
+		return ErrFrozen
+	}
+
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if e.shadow != nil {
+		e.shadow[key] = value
+	}
+
+	if e.process {
+		return os.Setenv(key, value)
+	}
+
+	if e.vars == nil {
+		e.vars = map[string]string{}
+	}
+	e.vars[key] = value
+
+	return nil
+}
+
+func (e *Env) unsetenv(key string) error {
+	if e.frozen {
+		return ErrFrozen
+	}
+
+	delete(e.shadow, key)
+
+	if e.process {
+		return os.Unsetenv(key)
+	}
+
+	delete(e.vars, key)
+	return nil
+}
+
+func (e *Env) clearenv() error {
+	if e.frozen {
+		return ErrFrozen
+	}
+
+	if e.shadow != nil {
+		e.shadow = map[string]string{}
+	}
+
+	if e.process {
+		os.Clearenv()
+		return nil
+	}
+
+	e.vars = nil
+
+	return nil
+}
+
+// Determine if a key is a valid POSIX shell identifier, made up of letters,
+// digits and underscores and not starting with a digit. Other keys may still
+// be set (see Set), but many tools cannot read them.
+func ValidKey(key string) bool {
+	if key == EmptyString {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if !isNameByte(key[i], i == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Ensure a key can be set, returning a descriptive error if it is empty or
+// contains "=" or a NUL byte.
+func checkKey(key string) error {
+	switch {
+	case key == EmptyString:
+		return errors.New("env: key must not be empty")
+	case strings.ContainsRune(key, '='):
+		return fmt.Errorf("env: key %q must not contain '='", key)
+	case strings.ContainsRune(key, 0):
+		return fmt.Errorf("env: key %q must not contain a NUL byte", key)
+	}
+
+	return nil
+}
+
+// Read the whole environment into a map.
+func (e *Env) environ() map[string]string {
+	vals := make(map[string]string, len(e.vars))
+
+	if !e.process {
+		for k, v := range e.vars {
+			vals[k] = v
+		}
+		return vals
+	}
+
+	for _, kv := range os.Environ() {
+		if k, v, _ := strings.Cut(kv, "="); k != EmptyString {
+			vals[k] = v
+		}
+	}
+
+	return vals
+}
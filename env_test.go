@@ -0,0 +1,111 @@
+package env
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestSetInvalidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"", "env: key must not be empty"},
+		{"A=B", `env: key "A=B" must not contain '='`},
+		{"A\x00B", `env: key "A\x00B" must not contain a NUL byte`},
+	}
+
+	envs := map[string]*Env{
+		"process": std,
+		"New":     New(nil),
+	}
+
+	for name, e := range envs {
+		for _, tt := range tests {
+			err := e.Set(tt.key, "value")
+			if err == nil {
+				t.Errorf("%s: Set(%q) succeeded, want error", name, tt.key)
+				continue
+			}
+			if err.Error() != tt.want {
+				t.Errorf("%s: Set(%q) error = %q, want %q", name, tt.key, err, tt.want)
+			}
+			if _, ok := e.Lookup(tt.key); ok {
+				t.Errorf("%s: Set(%q) set the key despite failing", name, tt.key)
+			}
+		}
+	}
+}
+
+func TestSetDefaultOKConcurrent(t *testing.T) {
+	e := New(nil)
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 100)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			set, err := e.SetDefaultOK("KEY", value)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- set
+		}(string(rune('a' + i%26)))
+	}
+	wg.Wait()
+	close(results)
+
+	n := 0
+	for set := range results {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("set reported by %d goroutines, want exactly 1", n)
+	}
+}
+
+func TestClearFrozen(t *testing.T) {
+	e := New(map[string]string{"APP_A": "1", "B": "2"})
+	e.Freeze()
+
+	if err := e.Clear(); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Clear error = %v, want ErrFrozen", err)
+	}
+	if err := e.ClearPrefix("APP_"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("ClearPrefix error = %v, want ErrFrozen", err)
+	}
+
+	// Calls with nothing to change fail as well.
+	noops := map[string]func() error{
+		"ClearPrefix":    func() error { return e.ClearPrefix("NONE_") },
+		"UnsetPrefix":    func() error { return e.UnsetPrefix("NONE_") },
+		"UnsetMatching":  func() error { return e.UnsetMatching("NONE_*") },
+		"Unset":          func() error { return e.Unset("NONE") },
+		"SetDefault":     func() error { return e.SetDefault("B", "3") },
+		"CompareAndSwap": func() error { _, err := e.CompareAndSwap("B", "3", "4"); return err },
+		"Restore":        func() error { return e.Restore(map[string]string{"APP_A": "1", "B": "2"}) },
+		"WithMap":        func() error { return e.WithMap(nil, func() {}) },
+		"Commit":         func() error { return e.NewBatch().Commit() },
+	}
+	for name, fn := range noops {
+		if err := fn(); !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s error = %v, want ErrFrozen", name, err)
+		}
+	}
+
+	if e.Get("APP_A") != "1" || e.Get("B") != "2" {
+		t.Errorf("frozen environment changed to %q", e.All())
+	}
+
+	e.Unfreeze()
+	if err := e.ClearPrefix("APP_"); err != nil || e.IsSet("APP_A") || !e.IsSet("B") {
+		t.Errorf("ClearPrefix = %v, left %q", err, e.All())
+	}
+	if err := e.Clear(); err != nil || e.IsSet("B") {
+		t.Errorf("Clear = %v, left %q", err, e.All())
+	}
+}
@@ -0,0 +1,47 @@
+// Package envtest provides helpers for tests that change environment
+// variables, restoring them automatically when each test finishes. It is kept
+// apart from package env so that production builds do not import testing.
+package envtest
+
+import (
+	"testing"
+
+	"github.com/jcoene/env"
+)
+
+// Set an environment variable for the duration of a test, returning it to its
+// prior state (including unset) when the test and its subtests finish. Fails
+// the test if the variable cannot be set.
+func SetForTest(t testing.TB, key, value string) {
+	t.Helper()
+
+	SetMapForTest(t, map[string]string{key: value})
+}
+
+// Set several environment variables for the duration of a test, see
+// SetForTest.
+func SetMapForTest(t testing.TB, vals map[string]string) {
+	t.Helper()
+
+	for key, value := range vals {
+		key := key
+		prior, ok := env.Lookup(key)
+
+		t.Cleanup(func() {
+			var err error
+			if ok {
+				err = env.Set(key, prior)
+			} else {
+				err = env.Unset(key)
+			}
+
+			if err != nil {
+				t.Errorf("envtest: restoring %s: %v", key, err)
+			}
+		})
+
+		if err := env.Set(key, value); err != nil {
+			t.Fatalf("envtest: setting %s: %v", key, err)
+		}
+	}
+}
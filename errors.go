@@ -0,0 +1,77 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The error returned when changing a frozen environment, see Freeze.
+var ErrFrozen = errors.New("env: environment is frozen")
+
+// An error returned when a required environment variable is unset or empty.
+type MissingError struct {
+	Key string
+}
+
+func (e *MissingError) Error() string {
+	return "env: missing required variable " + e.Key
+}
+
+func (e *MissingError) MissingKeys() []string {
+	return []string{e.Key}
+}
+
+// Implemented by errors that report missing variables, such as MissingError
+// and RequiredError, so callers can list them however they wish.
+type MissingKeyser interface {
+	error
+	MissingKeys() []string
+}
+
+// An error returned by RequireAll naming every required variable that is
+// unset or empty.
+type RequiredError struct {
+	Keys []string
+}
+
+func (e *RequiredError) Error() string {
+	return "env: missing required variables: " + strings.Join(e.Keys, ", ")
+}
+
+func (e *RequiredError) MissingKeys() []string {
+	return e.Keys
+}
+
+// An error returned when the value of an environment variable cannot be
+// parsed as the requested type, such as "int" or "duration". Err holds the
+// underlying cause, if any.
+type ParseError struct {
+	Key, Value, Type string
+	Err              error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("env: key %s value %q is not a valid %s", e.Key, e.Value, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reduce a strconv error to its cause (such as strconv.ErrRange), as the
+// ParseError it is wrapped in already names the value.
+func numError(err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return ne.Err
+	}
+
+	return err
+}
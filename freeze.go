@@ -0,0 +1,57 @@
+package env
+
+// Freeze the environment once configuration is loaded, so that any later
+// attempt to change it through this package (such as Set, Unset, SetDefault
+// or Clear) fails with ErrFrozen, even if there would be nothing to change.
+// Changes made directly with os.Setenv and friends are not prevented.
+func Freeze() {
+	std.Freeze()
+}
+
+// Freeze the environment, see Freeze.
+func (e *Env) Freeze() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.frozen = true
+}
+
+// Allow the environment to be changed again after Freeze, such as in tests.
+func Unfreeze() {
+	std.Unfreeze()
+}
+
+// Allow the environment to be changed again after Freeze, such as in tests.
+func (e *Env) Unfreeze() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.frozen = false
+}
+
+// Determine if the environment is frozen, see Freeze.
+func IsFrozen() bool {
+	return std.IsFrozen()
+}
+
+// Determine if the environment is frozen, see Freeze.
+func (e *Env) IsFrozen() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.frozen
+}
+
+// Set an environment variable or panic if it cannot be set, such as because
+// the environment is frozen.
+func MustSet(key, value string) {
+	std.MustSet(key, value)
+}
+
+// Set an environment variable or panic if it cannot be set, such as because
+// the environment is frozen.
+func (e *Env) MustSet(key, value string) {
+	if err := e.Set(key, value); err != nil {
+		panic(err)
+	}
+}
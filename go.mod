@@ -0,0 +1,3 @@
+module github.com/jcoene/env
+
+go 1.20
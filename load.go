@@ -0,0 +1,1016 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// The longest line that may be loaded, which also limits the combined
+	// length of the lines of a multi-line value.
+	maxLineSize = 16 << 20
+
+	// A UTF-8 byte order mark, ignored at the start of loaded files.
+	byteOrderMark = "\uFEFF"
+)
+
+// Set a callback to be invoked with the key and source (the filename) of each
+// variable set while loading files, such as to log which file set what, or
+// nil to stop. Values are never passed, so secrets are not leaked. Each load
+// uses the callback set when it began.
+func SetLogger(fn func(key, source string)) {
+	std.SetLogger(fn)
+}
+
+// Set a callback invoked for each variable set while loading files, see
+// SetLogger.
+func (e *Env) SetLogger(fn func(key, source string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.logger = fn
+}
+
+// The callback set with SetLogger, or nil.
+func (e *Env) loadLogger() func(key, source string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.logger
+}
+
+// Load the environment variables from the ".env" file, overwriting any that
+// are already set.
+func Load() error {
+	return std.Load()
+}
+
+// Load the environment variables from the ".env" file, overwriting any that
+// are already set.
+func (e *Env) Load() error {
+	return e.LoadFile(DefaultFile)
+}
+
+// Load the environment variables from the ".env" file, preserving any that are
+// already set.
+func LoadDefaults() error {
+	return std.LoadDefaults()
+}
+
+// Load the environment variables from the ".env" file, preserving any that are
+// already set.
+func (e *Env) LoadDefaults() error {
+	return e.LoadFileDefaults(DefaultFile)
+}
+
+// Load the environment variables from the ".env" file as Load does, but
+// return no error if it does not exist.
+func LoadOptional() error {
+	return std.LoadOptional()
+}
+
+// Load the environment variables from the ".env" file as Load does, but
+// return no error if it does not exist.
+func (e *Env) LoadOptional() error {
+	return e.LoadFileOptional(DefaultFile)
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// return no error if it does not exist. Errors reading or parsing a file that
+// does exist are still returned.
+func LoadFileOptional(name string) error {
+	return std.LoadFileOptional(name)
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// return no error if it does not exist. Errors reading or parsing a file that
+// does exist are still returned.
+func (e *Env) LoadFileOptional(name string) error {
+	return e.LoadFilesOptional(name)
+}
+
+// Load the environment variables from the ".env" file as Load does, or panic
+// if it cannot be loaded.
+func MustLoad() {
+	std.MustLoad()
+}
+
+// Load the environment variables from the ".env" file as Load does, or panic
+// if it cannot be loaded.
+func (e *Env) MustLoad() {
+	e.MustLoadFile(DefaultFile)
+}
+
+// Load environment variables from a given filename as LoadFile does, or panic
+// if it cannot be loaded.
+func MustLoadFile(name string) {
+	std.MustLoadFile(name)
+}
+
+// Load environment variables from a given filename as LoadFile does, or panic
+// if it cannot be loaded.
+func (e *Env) MustLoadFile(name string) {
+	if err := e.LoadFile(name); err != nil {
+		panic(fmt.Sprintf("env: loading %s: %v", name, err))
+	}
+}
+
+// Load environment variables from a given filename. Blank lines and lines
+// beginning with "#" are ignored, as is a leading "export" keyword and
+// whitespace surrounding keys and values (see LoadOptions). Lines may
+// end in "\n" or "\r\n", and a leading UTF-8 byte order mark is ignored.
+//
+// Values wrapped in single quotes are taken literally. Otherwise $VAR and
+// ${VAR} references are expanded against the environment as it stands when
+// the line is read, so may refer to earlier entries in the file. Undefined
+// variables expand to an empty string. The shell forms ${VAR:-default} (used
+// if VAR is unset or empty) and ${VAR:+alternate} (used if VAR is set and
+// non-empty) are also supported. Values wrapped in double quotes also
+// have escape sequences such as \n, \t and \$ interpreted.
+//
+// A value opening with a double quote that is not closed on the same line
+// continues onto the following lines until the closing quote, keeping the
+// newlines between them.
+//
+// Only the first "=" on a line separates the key from the value, so values
+// may contain "=" whether quoted or not. A "#" within a value is kept, except
+// that a quoted value may be followed by a comment, as in
+// URL="postgres://h/db?sslmode=require" # primary database.
+//
+// Variables that are already set are overwritten, see LoadFileDefaults to
+// preserve them instead.
+func LoadFile(name string) error {
+	return std.LoadFile(name)
+}
+
+// Load environment variables from a given filename, see LoadFile.
+func (e *Env) LoadFile(name string) error {
+	return e.LoadFileWith(name, LoadOptions{})
+}
+
+// Load environment variables from a given filename, preserving any that are
+// already set so that the real environment takes precedence over the file.
+// The file is parsed as described in LoadFile.
+func LoadFileDefaults(name string) error {
+	return std.LoadFileDefaults(name)
+}
+
+// Load environment variables from a given filename, preserving any that are
+// already set so that the real environment takes precedence over the file.
+// The file is parsed as described in LoadFile.
+func (e *Env) LoadFileDefaults(name string) error {
+	return e.LoadFileWith(name, LoadOptions{Defaults: true})
+}
+
+// Load environment variables from a given filename as LoadFile does,
+// returning the number of variables set. Comments and blank lines are not
+// counted.
+func LoadFileCount(name string) (int, error) {
+	return std.LoadFileCount(name)
+}
+
+// Load environment variables from a given filename as LoadFile does,
+// returning the number of variables set. Comments and blank lines are not
+// counted.
+func (e *Env) LoadFileCount(name string) (n int, err error) {
+	set := func(key, value string) (err error) {
+		if err = e.Set(key, value); err == nil {
+			n++
+		}
+		return
+	}
+
+	err = loader{set: set, lookup: e.Lookup, log: e.loadLogger()}.file(name)
+
+	return
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// continue past entries that fail to be set, returning an error listing each
+// of them.
+func LoadFileAll(name string) error {
+	return std.LoadFileAll(name)
+}
+
+// Load environment variables from a given filename, see LoadFileAll.
+func (e *Env) LoadFileAll(name string) error {
+	var errs []error
+
+	set := func(key, value string) error {
+		if err := e.Set(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("env: setting %s: %w", key, err))
+			return errSkipped
+		}
+		return nil
+	}
+
+	if err := (loader{set: set, lookup: e.Lookup, log: e.loadLogger()}).file(name); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Load environment variables from a given filename as LoadFile does,
+// returning each key that is defined more than once in the file, in the order
+// in which they are first repeated. The last definition of a duplicate wins.
+func LoadFileReportDups(name string) ([]string, error) {
+	return std.LoadFileReportDups(name)
+}
+
+// Load environment variables from a given filename, reporting duplicate keys,
+// see LoadFileReportDups.
+func (e *Env) LoadFileReportDups(name string) (dups []string, err error) {
+	seen := map[string]int{}
+
+	set := func(key, value string) error {
+		if seen[key]++; seen[key] == 2 {
+			dups = append(dups, key)
+		}
+		return e.Set(key, value)
+	}
+
+	err = loader{set: set, lookup: e.Lookup, log: e.loadLogger()}.file(name)
+
+	return
+}
+
+// Load only the environment variables from a given filename whose keys pass
+// the keep predicate. The whole file is parsed as ParseFile does before any
+// variable is set, so entries may still refer to those that are skipped.
+func LoadFileFilter(name string, keep func(key string) bool) error {
+	return std.LoadFileFilter(name, keep)
+}
+
+// Load only the environment variables from a given filename whose keys pass
+// the keep predicate, see LoadFileFilter.
+func (e *Env) LoadFileFilter(name string, keep func(key string) bool) error {
+	vals, err := e.ParseFile(name)
+	if err != nil {
+		return err
+	}
+
+	log := e.loadLogger()
+	for k, v := range vals {
+		if !keep(k) {
+			continue
+		}
+
+		if err = e.Set(k, v); err != nil {
+			return err
+		} else if log != nil {
+			log(k, name)
+		}
+	}
+
+	return nil
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// pass each key through transform before it is set, such as to turn
+// "server.port" into "SERVER_PORT". Entries whose transformed key is empty
+// are skipped.
+func LoadFileTransform(name string, transform func(key string) string) error {
+	return std.LoadFileTransform(name, transform)
+}
+
+// Load environment variables from a given filename with keys passed through
+// transform, see LoadFileTransform.
+func (e *Env) LoadFileTransform(name string, transform func(key string) string) error {
+	return loader{set: e.Set, lookup: e.Lookup, log: e.loadLogger(), transform: transform}.file(name)
+}
+
+// Load the environment variables from a given filename except those named in
+// exclude, which are matched exactly and skipped, such as to keep a shared
+// file from overriding PATH or HOME. See LoadFileFilter.
+func LoadFileExcept(name string, exclude ...string) error {
+	return std.LoadFileExcept(name, exclude...)
+}
+
+// Load the environment variables from a given filename except those named in
+// exclude, see LoadFileExcept.
+func (e *Env) LoadFileExcept(name string, exclude ...string) error {
+	skip := make(map[string]bool, len(exclude))
+	for _, key := range exclude {
+		skip[key] = true
+	}
+
+	return e.LoadFileFilter(name, func(key string) bool {
+		return !skip[key]
+	})
+}
+
+// Load environment variables from each of the given filenames in order with
+// LoadFile, so that later files override earlier ones. Stops at the first
+// error.
+func LoadFiles(names ...string) (err error) {
+	return std.LoadFiles(names...)
+}
+
+// Load environment variables from each of the given filenames in order with
+// LoadFile, so that later files override earlier ones. Stops at the first
+// error.
+func (e *Env) LoadFiles(names ...string) (err error) {
+	for _, name := range names {
+		if err = e.LoadFile(name); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Load environment variables from each of the given filenames as LoadFiles
+// does, skipping any that do not exist.
+func LoadFilesOptional(names ...string) (err error) {
+	return std.LoadFilesOptional(names...)
+}
+
+// Load environment variables from each of the given filenames as LoadFiles
+// does, skipping any that do not exist.
+func (e *Env) LoadFilesOptional(names ...string) (err error) {
+	for _, name := range names {
+		if err = e.LoadFile(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+
+	return nil
+}
+
+// Load environment variables from the ".env" file in baseDir, followed by
+// ".env.<env>" and ".env.<env>.local" where env is the value of the variable
+// named by DefaultEnvironmentKey (which may itself be set by ".env"). Later
+// files override earlier ones and files that do not exist are skipped.
+func LoadForEnv(baseDir string) (err error) {
+	return std.LoadForEnv(baseDir)
+}
+
+// Load environment-specific files from baseDir, see LoadForEnv.
+func (e *Env) LoadForEnv(baseDir string) (err error) {
+	return e.LoadForEnvKey(baseDir, DefaultEnvironmentKey)
+}
+
+// Load environment-specific files from baseDir as LoadForEnv does, with the
+// current environment named by the variable key rather than
+// DefaultEnvironmentKey.
+func LoadForEnvKey(baseDir, key string) (err error) {
+	return std.LoadForEnvKey(baseDir, key)
+}
+
+// Load environment-specific files from baseDir, with the current environment
+// named by key, see LoadForEnvKey.
+func (e *Env) LoadForEnvKey(baseDir, key string) (err error) {
+	base := filepath.Join(baseDir, DefaultFile)
+
+	if err = e.LoadFilesOptional(base); err != nil {
+		return
+	}
+
+	if name := e.Get(key); name != EmptyString {
+		err = e.LoadFilesOptional(base+"."+name, base+"."+name+".local")
+	}
+
+	return
+}
+
+// Configure the environment from layered sources. In order of precedence:
+//
+//  1. variables already set (and non-empty) in the environment
+//  2. the given files, with later files overriding earlier ones
+//  3. the given defaults
+//
+// So no file or default overrides a variable that is already set, and
+// defaults only fill in what no file provides. Files that do not exist are
+// skipped. References within a file resolve by the same precedence, to the
+// value the variable will end up with, so a file may refer to variables from
+// earlier files (or earlier in the same file) and to the defaults.
+func Configure(files []string, defaults map[string]string) error {
+	return std.Configure(files, defaults)
+}
+
+// Configure the environment from files and defaults, see Configure.
+func (e *Env) Configure(files []string, defaults map[string]string) error {
+	vals, sources := map[string]string{}, map[string]string{}
+
+	lookup := func(key string) (string, bool) {
+		if v := e.Get(key); v != EmptyString {
+			return v, true
+		}
+		if v, ok := vals[key]; ok {
+			return v, true
+		}
+		if v, ok := defaults[key]; ok {
+			return v, true
+		}
+		return e.Lookup(key)
+	}
+
+	for _, name := range files {
+		name := name
+		set := func(key, value string) error {
+			vals[key], sources[key] = value, name
+			return nil
+		}
+
+		err := loader{set: set, lookup: lookup}.file(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+
+	log := e.loadLogger()
+	for k, v := range vals {
+		if set, err := e.SetDefaultOK(k, v); err != nil {
+			return err
+		} else if set && log != nil {
+			log(k, sources[k])
+		}
+	}
+
+	return e.SetDefaults(defaults)
+}
+
+// Load environment variables from a reader, parsed as described in LoadFile.
+func LoadReader(r io.Reader) error {
+	return std.LoadReader(r)
+}
+
+// Load environment variables from a reader, parsed as described in LoadFile.
+func (e *Env) LoadReader(r io.Reader) error {
+	return loader{set: e.Set, lookup: e.Lookup}.reader(r)
+}
+
+// Load environment variables from a string holding the contents of a file,
+// parsed as described in LoadFile.
+func LoadString(data string) error {
+	return std.LoadString(data)
+}
+
+// Load environment variables from a string holding the contents of a file,
+// parsed as described in LoadFile.
+func (e *Env) LoadString(data string) error {
+	return e.LoadReader(strings.NewReader(data))
+}
+
+// Parse environment variables from a reader without setting them. Entries
+// are parsed as described in LoadFile, with references resolved against
+// earlier entries before the environment.
+func Parse(r io.Reader) (vals map[string]string, err error) {
+	return std.Parse(r)
+}
+
+// Parse environment variables from a reader without setting them. Entries
+// are parsed as described in LoadFile, with references resolved against
+// earlier entries before the environment.
+func (e *Env) Parse(r io.Reader) (vals map[string]string, err error) {
+	vals = map[string]string{}
+
+	l := loader{
+		set: func(key, value string) error {
+			vals[key] = value
+			return nil
+		},
+		lookup: func(key string) (string, bool) {
+			if v, ok := vals[key]; ok {
+				return v, true
+			}
+			return e.Lookup(key)
+		},
+	}
+
+	if err = l.reader(r); err != nil {
+		vals = nil
+	}
+
+	return
+}
+
+// Parse environment variables from a given filename without setting them.
+// See Parse.
+func ParseFile(name string) (vals map[string]string, err error) {
+	return std.ParseFile(name)
+}
+
+// Parse environment variables from a given filename without setting them.
+// See Parse.
+func (e *Env) ParseFile(name string) (vals map[string]string, err error) {
+	var file *os.File
+
+	if file, err = os.Open(name); err != nil {
+		return
+	}
+	defer file.Close()
+
+	return e.Parse(file)
+}
+
+// Parse environment variables from a string holding the contents of a file
+// without setting them. See Parse.
+func ParseString(data string) (vals map[string]string, err error) {
+	return std.ParseString(data)
+}
+
+// Parse environment variables from a string holding the contents of a file
+// without setting them. See Parse.
+func (e *Env) ParseString(data string) (vals map[string]string, err error) {
+	return e.Parse(strings.NewReader(data))
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// return an error naming the line number of any malformed entry (a line
+// lacking "=" or with an empty key) rather than skipping over it, or of any
+// reference to an undefined variable.
+func LoadFileStrict(name string) error {
+	return std.LoadFileStrict(name)
+}
+
+// Load environment variables from a given filename, see LoadFileStrict.
+func (e *Env) LoadFileStrict(name string) error {
+	return e.LoadFileWith(name, LoadOptions{Strict: true})
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// return an error naming the line number of any entry whose key is not a
+// valid shell identifier (see ValidKey) rather than setting it.
+func LoadFileValidated(name string) error {
+	return std.LoadFileValidated(name)
+}
+
+// Load environment variables from a given filename, see LoadFileValidated.
+func (e *Env) LoadFileValidated(name string) error {
+	return e.LoadFileWith(name, LoadOptions{ValidKeys: true})
+}
+
+// Load environment variables from a given filename as LoadFile does, but
+// resolve references against base before the environment, so that
+// interpolation does not depend on whatever the environment holds. References
+// found in neither expand to an empty string, or may be reported by also
+// setting the Strict option of LoadFileWith.
+func LoadFileBase(name string, base map[string]string) error {
+	return std.LoadFileBase(name, base)
+}
+
+// Load environment variables from a given filename with references resolved
+// against base, see LoadFileBase.
+func (e *Env) LoadFileBase(name string, base map[string]string) error {
+	return e.LoadFileWith(name, LoadOptions{Base: base})
+}
+
+// Options controlling how files are loaded by LoadFileWith. The zero value
+// loads files exactly as LoadFile does.
+type LoadOptions struct {
+	// The separator between keys and values, "=" if empty.
+	Separator string
+
+	// The prefix marking comment lines, "#" if empty.
+	Comment string
+
+	// Keep whitespace surrounding values rather than trimming it.
+	NoTrim bool
+
+	// Keep trailing whitespace in values, trimming only leading whitespace.
+	// Keys are always trimmed.
+	KeepTrailingSpace bool
+
+	// Keep variable references in values rather than expanding them.
+	NoExpand bool
+
+	// Preserve variables that are already set, as LoadFileDefaults does.
+	Defaults bool
+
+	// Report malformed entries and undefined references, as LoadFileStrict
+	// does.
+	Strict bool
+
+	// Report keys that are not valid shell identifiers, as LoadFileValidated
+	// does.
+	ValidKeys bool
+
+	// Variables that references resolve against before the environment, as
+	// LoadFileBase does.
+	Base map[string]string
+
+	// Treat lines of the form "include other.env" as directives to load the
+	// named file at that point, relative to the including file's directory.
+	// Files may be included more than once, but not from within themselves.
+	Include bool
+}
+
+// Load environment variables from a given filename as LoadFile does, with
+// the parsing and overwriting behavior adjusted by opts.
+func LoadFileWith(name string, opts LoadOptions) error {
+	return std.LoadFileWith(name, opts)
+}
+
+// Load environment variables from a given filename, see LoadFileWith.
+func (e *Env) LoadFileWith(name string, opts LoadOptions) error {
+	set := e.Set
+	if opts.Defaults {
+		set = func(key, value string) error {
+			if ok, err := e.SetDefaultOK(key, value); err != nil {
+				return err
+			} else if !ok {
+				return errSkipped
+			}
+			return nil
+		}
+	}
+
+	lookup := e.Lookup
+	if opts.Base != nil {
+		lookup = func(key string) (string, bool) {
+			if v, ok := opts.Base[key]; ok {
+				return v, true
+			}
+			return e.Lookup(key)
+		}
+	}
+
+	return loader{set: set, lookup: lookup, log: e.loadLogger(), opts: opts}.file(name)
+}
+
+// Returned by a loader's set function for an entry it deliberately did not
+// apply, such as one already set when loading defaults. Loading continues
+// and the entry is not logged.
+var errSkipped = errors.New("env: entry skipped")
+
+// Parses .env files, applying each entry with set and resolving references
+// with lookup. The Defaults option is left to the caller's choice of set.
+type loader struct {
+	set    func(key, value string) error
+	lookup func(key string) (string, bool)
+	opts   LoadOptions
+
+	// If not nil, called with the key and source of each entry set, where
+	// source is the name of the file being loaded, which differs from the
+	// top-level file for entries in included files.
+	log    func(key, source string)
+	source string
+
+	// The directory of the file being loaded, which included files are
+	// relative to, and the absolute paths of the files currently being loaded
+	// to detect include cycles.
+	dir     string
+	loading map[string]bool
+
+	// If not nil, applied to each key before it is set and logged, skipping
+	// entries whose transformed key is empty.
+	transform func(key string) string
+
+	// If not nil, updated with the line number of each entry before it is
+	// set.
+	line *int
+}
+
+// Load environment variables from a given filename.
+func (l loader) file(name string) (err error) {
+	var file *os.File
+
+	if l.opts.Include {
+		var abs string
+		if abs, err = filepath.Abs(name); err != nil {
+			return
+		}
+
+		if l.loading[abs] {
+			return fmt.Errorf("env: include cycle at %s", name)
+		}
+
+		if l.loading == nil {
+			l.loading = map[string]bool{}
+		}
+		l.loading[abs] = true
+		defer delete(l.loading, abs)
+
+		l.dir = filepath.Dir(name)
+	}
+
+	if file, err = os.Open(name); err != nil {
+		return
+	}
+	defer file.Close()
+
+	l.source = name
+
+	return l.reader(file)
+}
+
+// Read the path named by an include directive, reporting whether line is one.
+func (l loader) include(line string) (name string, ok bool) {
+	rest, found := strings.CutPrefix(line, "include")
+	if !l.opts.Include || !found || rest == EmptyString || (rest[0] != ' ' && rest[0] != '\t') {
+		return
+	}
+
+	if name = strings.TrimSpace(rest); strings.HasPrefix(name, l.separator()) {
+		return EmptyString, false
+	}
+
+	if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+		name = name[1 : len(name)-1]
+	}
+
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(l.dir, name)
+	}
+
+	return name, true
+}
+
+// Load environment variables from a reader.
+func (l loader) reader(r io.Reader) (err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if n == 1 {
+			text = strings.TrimPrefix(text, byteOrderMark)
+		}
+
+		line := strings.TrimSpace(text)
+		if line == EmptyString || strings.HasPrefix(line, l.comment()) {
+			continue
+		}
+
+		if name, ok := l.include(line); ok {
+			if err = l.file(name); err != nil {
+				return fmt.Errorf("env: line %d: including %s: %w", n, name, err)
+			}
+			continue
+		}
+
+		parts := strings.SplitN(trimExport(text), l.separator(), 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == EmptyString {
+			if l.opts.Strict {
+				return fmt.Errorf("env: line %d: malformed entry %q", n, line)
+			}
+			continue
+		}
+
+		key, value := strings.TrimSpace(parts[0]), parts[1]
+		if l.opts.ValidKeys && !ValidKey(key) {
+			return fmt.Errorf("env: line %d: invalid key %q", n, key)
+		}
+
+		if !l.opts.NoTrim {
+			value = strings.TrimLeft(value, " \t")
+		}
+
+		start := n
+		for ; unterminated(value); n++ {
+			if !scanner.Scan() {
+				if err = scanner.Err(); err == nil {
+					err = fmt.Errorf("env: line %d: unterminated quoted value for %s", start, key)
+				}
+				return
+			}
+
+			if value += "\n" + scanner.Text(); len(value) > maxLineSize {
+				return fmt.Errorf("env: line %d: value for %s is longer than %d bytes", start, key, maxLineSize)
+			}
+		}
+
+		if !l.opts.NoTrim && !l.opts.KeepTrailingSpace {
+			value = strings.TrimRight(value, " \t")
+		}
+
+		if value, err = l.value(value); err != nil {
+			return fmt.Errorf("env: line %d: %v", start, err)
+		}
+
+		if l.line != nil {
+			*l.line = start
+		}
+
+		if l.transform != nil {
+			if key = l.transform(key); key == EmptyString {
+				continue
+			}
+		}
+
+		if err = l.set(key, value); err == errSkipped {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if l.log != nil {
+			l.log(key, l.source)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// The separator between keys and values.
+func (l loader) separator() string {
+	if l.opts.Separator == EmptyString {
+		return "="
+	}
+
+	return l.opts.Separator
+}
+
+// The prefix marking comment lines.
+func (l loader) comment() string {
+	if l.opts.Comment == EmptyString {
+		return "#"
+	}
+
+	return l.opts.Comment
+}
+
+// Determine if a value opens a double quote without closing it, meaning it
+// continues onto the following lines.
+func unterminated(value string) bool {
+	return strings.HasPrefix(value, "\"") && closingQuote(value) < 0
+}
+
+// Find the quote closing the one that opens value, skipping backslash escaped
+// quotes within double quotes. Returns -1 if there is none.
+func closingQuote(value string) int {
+	q := value[0]
+
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && q == '"':
+			i++
+		case value[i] == q:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Remove a leading "export" keyword from a line written to be sourced by a
+// shell.
+func trimExport(line string) string {
+	rest := strings.TrimPrefix(strings.TrimLeft(line, " \t"), "export")
+	if len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t') {
+		return strings.TrimLeft(rest, " \t")
+	}
+
+	return line
+}
+
+// Strip matching single or double quotes surrounding a value, along with any
+// comment following the closing quote. Values in single quotes are taken
+// literally, otherwise variable references are expanded and, within double
+// quotes, escape sequences interpreted.
+func (l loader) value(value string) (string, error) {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') {
+		if end := closingQuote(value); end > 0 {
+			rest := strings.TrimLeft(value[end+1:], " \t")
+			if rest == EmptyString || strings.HasPrefix(rest, l.comment()) {
+				value = value[:end+1]
+			}
+		}
+	}
+
+	if len(value) >= 2 {
+		switch q := value[0]; {
+		case q == '\'' && value[len(value)-1] == q:
+			return value[1 : len(value)-1], nil
+		case q == '"' && value[len(value)-1] == q:
+			return l.expand(value[1:len(value)-1], true)
+		}
+	}
+
+	return l.expand(value, false)
+}
+
+// Expand $VAR and ${VAR} references against the current environment and,
+// if escapes is true, interpret backslash escape sequences. Unknown escape
+// sequences are kept as-is. Undefined variables expand to an empty string, or
+// are an error in strict mode. Braced references may also use the shell
+// operators ${VAR:-word} and ${VAR:+word}, see resolve.
+func (l loader) expand(s string, escapes bool) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && escapes && i < len(s)-1:
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+
+		case s[i] == '$' && !l.opts.NoExpand:
+			ref, width := varRef(s[i+1:])
+			if width == 0 {
+				b.WriteByte(s[i])
+				continue
+			}
+
+			v, err := l.resolve(ref, escapes)
+			if err != nil {
+				return EmptyString, err
+			}
+
+			b.WriteString(v)
+			i += width
+
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// A variable reference, with the operator and word of ${VAR:-word} and
+// ${VAR:+word} forms.
+type reference struct {
+	name, op, word string
+}
+
+// Resolve a variable reference. With ":-" the word (itself expanded) is used
+// if the variable is unset or empty, and with ":+" it is used if the variable
+// is set and non-empty, otherwise the result is empty. No other operators are
+// supported.
+func (l loader) resolve(ref reference, escapes bool) (string, error) {
+	v, ok := l.lookup(ref.name)
+
+	switch {
+	case ref.op == ":-" && v == EmptyString, ref.op == ":+" && v != EmptyString:
+		return l.expand(ref.word, escapes)
+	case ref.op == ":+":
+		return EmptyString, nil
+	case !ok && ref.op == EmptyString && l.opts.Strict:
+		return EmptyString, fmt.Errorf("undefined variable %s", ref.name)
+	}
+
+	return v, nil
+}
+
+// Read the variable reference at the start of s, which follows a "$", in
+// either braced or bare form. Braces within a braced reference must balance,
+// so words may contain references of their own as in ${A:-${B}}. Returns the
+// reference and the number of bytes it occupies, or a width of 0 if s does
+// not start with one.
+func varRef(s string) (ref reference, width int) {
+	if !strings.HasPrefix(s, "{") {
+		for width < len(s) && isNameByte(s[width], width == 0) {
+			width++
+		}
+
+		return reference{name: s[:width]}, width
+	}
+
+	depth := 0
+	for end := 0; end < len(s); end++ {
+		switch s[end] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth > 0 {
+				continue
+			}
+
+			if end == 1 {
+				return
+			}
+
+			ref.name = s[1:end]
+			n := 0
+			for n < len(ref.name) && isNameByte(ref.name[n], n == 0) {
+				n++
+			}
+
+			if rest := ref.name[n:]; n > 0 && (strings.HasPrefix(rest, ":-") || strings.HasPrefix(rest, ":+")) {
+				ref.name, ref.op, ref.word = ref.name[:n], rest[:2], rest[2:]
+			}
+
+			return ref, end + 1
+		}
+	}
+
+	return
+}
+
+// Determine if c may appear in a bare variable name. Digits may not start one.
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (!first && '0' <= c && c <= '9')
+}
@@ -0,0 +1,411 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Write a file into a temporary directory, returning its path.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	name = filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return name
+}
+
+func TestConfigureReferences(t *testing.T) {
+	base := writeFile(t, ".env", "BASE=/srv\nLOG=${BASE}/log\n")
+	local := writeFile(t, ".env.local", "DATA=${BASE}/data\nCACHE=${TMP}/cache\n")
+
+	e := New(map[string]string{"LOG": "/var/log"})
+	if err := e.Configure([]string{base, local}, map[string]string{"TMP": "/tmp"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"BASE":  "/srv",
+		"LOG":   "/var/log",
+		"DATA":  "/srv/data",
+		"CACHE": "/tmp/cache",
+		"TMP":   "/tmp",
+	}
+	for k, v := range want {
+		if got := e.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestConfigurePrecedence(t *testing.T) {
+	a := writeFile(t, "a.env", "X=a\nY=a\n")
+	b := writeFile(t, "b.env", "Y=b\nDERIVED=${R}\n")
+	missing := filepath.Join(t.TempDir(), "missing.env")
+
+	e := New(map[string]string{"R": "real"})
+	if err := e.Configure([]string{a, missing, b}, map[string]string{"X": "default", "Z": "default", "R": "default"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"X": "a", "Y": "b", "Z": "default", "R": "real", "DERIVED": "real"}
+	for k, v := range want {
+		if got := e.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	name := writeFile(t, ".env", "A=1\nB=2\n")
+
+	e := New(nil)
+	var got []string
+	e.SetLogger(func(key, source string) {
+		got = append(got, key+" "+filepath.Base(source))
+	})
+	if err := e.LoadFile(name); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "A .env" || got[1] != "B .env" {
+		t.Errorf("logged %q, want [\"A .env\" \"B .env\"]", got)
+	}
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+	name := writeFile(t, ".env", "A=1\n")
+
+	e := New(nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			e.SetLogger(func(key, source string) {})
+			e.SetLogger(nil)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := e.LoadFile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestSetLoggerInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"c.env": "C=1\ninclude d.env\nE=3\n",
+		"d.env": "D=2\n",
+	})
+
+	e := New(map[string]string{"E": "set"})
+	var got []string
+	e.SetLogger(func(key, source string) {
+		got = append(got, key+" "+filepath.Base(source))
+	})
+	if err := e.LoadFileWith(filepath.Join(dir, "c.env"), LoadOptions{Include: true, Defaults: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"C c.env", "D d.env"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`URL="postgres://u:p@h/db?a=b"`, "postgres://u:p@h/db?a=b"},
+		{`A="x" # c`, "x"},
+		{`A='a#b' # c`, "a#b"},
+		{`A=a=b`, "a=b"},
+		{`A="say \"hi\""`, `say "hi"`},
+		{`A="x\"" # c`, `x"`},
+		{`A="a\\b"`, `a\b`},
+	}
+
+	for _, tt := range tests {
+		vals, err := New(nil).ParseString(tt.data)
+		if err != nil {
+			t.Errorf("ParseString(%q): %v", tt.data, err)
+			continue
+		}
+		for _, v := range vals {
+			if v != tt.want {
+				t.Errorf("ParseString(%q) = %q, want %q", tt.data, v, tt.want)
+			}
+		}
+		if len(vals) != 1 {
+			t.Errorf("ParseString(%q) parsed %d entries, want 1", tt.data, len(vals))
+		}
+	}
+}
+
+func TestParseLineEndings(t *testing.T) {
+	tests := map[string]string{
+		"CRLF": "A=1\r\nB=\"two\"\r\n# comment\r\nC='3'\r\n",
+		"BOM":  "\ufeffA=1\nB=\"two\"\nC='3'\n",
+	}
+	want := map[string]string{"A": "1", "B": "two", "C": "3"}
+
+	for name, data := range tests {
+		vals, err := New(nil).ParseString(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(vals) != len(want) {
+			t.Errorf("%s: parsed %q, want %q", name, vals, want)
+		}
+		for k, v := range want {
+			if vals[k] != v {
+				t.Errorf("%s: %s = %q, want %q", name, k, vals[k], v)
+			}
+		}
+
+		e := New(nil)
+		if err := e.LoadString(data); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for k, v := range want {
+			if got := e.Get(k); got != v {
+				t.Errorf("%s: loaded %s = %q, want %q", name, k, got, v)
+			}
+		}
+	}
+}
+
+func TestSetLoggerTransform(t *testing.T) {
+	name := writeFile(t, ".env", "server.port=80\nskip=1\n")
+
+	e := New(nil)
+	var got []string
+	e.SetLogger(func(key, source string) {
+		got = append(got, key)
+	})
+	err := e.LoadFileTransform(name, func(key string) string {
+		if key == "skip" {
+			return ""
+		}
+		return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0] != "SERVER_PORT" {
+		t.Errorf("logged %q, want [SERVER_PORT]", got)
+	}
+	if v := e.Get("SERVER_PORT"); v != "80" {
+		t.Errorf("SERVER_PORT = %q, want 80", v)
+	}
+}
+
+func TestLoadTrailingSpace(t *testing.T) {
+	name := writeFile(t, ".env", "A=  x  \n")
+
+	tests := []struct {
+		opts LoadOptions
+		want string
+	}{
+		{LoadOptions{}, "x"},
+		{LoadOptions{KeepTrailingSpace: true}, "x  "},
+		{LoadOptions{NoTrim: true}, "  x  "},
+	}
+
+	for _, tt := range tests {
+		e := New(nil)
+		if err := e.LoadFileWith(name, tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		if got := e.Get("A"); got != tt.want {
+			t.Errorf("LoadFileWith(%+v): A = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestLoadForEnvKey(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":             "STAGE=test\nA=base\n",
+		".env.test":        "A=test\nB=test\n",
+		".env.test.local":  "B=local\n",
+		".env.other.local": "B=other\n",
+	})
+
+	e := New(map[string]string{DefaultEnvironmentKey: "other"})
+	if err := e.LoadForEnvKey(dir, "STAGE"); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := e.Get("A"), e.Get("B"); a != "test" || b != "local" {
+		t.Errorf("A, B = %q, %q, want test, local", a, b)
+	}
+}
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"R=$HOME/x", "/home/u/x"},
+		{"R=${HOME}x", "/home/ux"},
+		{"B=b\nR=a${B}c", "abc"},
+		{"B=b\nB=${B}${B}\nR=$B", "bb"},
+		{"R=$UNDEFINED.", "."},
+		{"R=$$", "$$"},
+		{"R=$5", "$5"},
+		{"R=a$", "a$"},
+		{"R=${}", "${}"},
+		{"R=${HOME", "${HOME"},
+		{`R="\$HOME"`, "$HOME"},
+		{"R='$HOME'", "$HOME"},
+		{"R=${UNDEFINED:-fallback}", "fallback"},
+		{"R=${EMPTY:-fallback}", "fallback"},
+		{"R=${HOME:-fallback}", "/home/u"},
+		{"R=${HOME:+set}", "set"},
+		{"R=${UNDEFINED:+set}", ""},
+		{"R=${UNDEFINED:-${HOME}/d}", "/home/u/d"},
+		{"R=${UNDEFINED:-${ALSO:-{x}}}", "{x}"},
+		{"R=${UNDEFINED:-a}}", "a}"},
+	}
+
+	for _, tt := range tests {
+		vals, err := New(map[string]string{"HOME": "/home/u", "EMPTY": ""}).ParseString(tt.data)
+		if err != nil {
+			t.Errorf("ParseString(%q): %v", tt.data, err)
+			continue
+		}
+		if got := vals["R"]; got != tt.want {
+			t.Errorf("ParseString(%q): R = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStrictUndefined(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"A=1\nR=$UNDEFINED\n", "env: line 2: undefined variable UNDEFINED"},
+		{"R=${UNDEFINED}\n", "env: line 1: undefined variable UNDEFINED"},
+		{"R=${OUTER:-$UNDEFINED}\n", "env: line 1: undefined variable UNDEFINED"},
+		{"R=${UNDEFINED:-ok}\n", ""},
+		{"R=${UNDEFINED:+ok}\n", ""},
+		{"R=$EMPTY\n", ""},
+	}
+
+	for _, tt := range tests {
+		name := writeFile(t, ".env", tt.data)
+		err := New(map[string]string{"EMPTY": ""}).LoadFileWith(name, LoadOptions{Strict: true})
+		if tt.want == "" && err != nil {
+			t.Errorf("LoadFileWith(%q): %v", tt.data, err)
+		} else if tt.want != "" && (err == nil || err.Error() != tt.want) {
+			t.Errorf("LoadFileWith(%q) error = %v, want %s", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestParseMultiline(t *testing.T) {
+	data := "A=1\nKEY=\"-----BEGIN KEY-----\nMIIB\nabc=\n-----END KEY-----\"\nB=2\n"
+
+	vals, err := New(nil).ParseString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"A":   "1",
+		"KEY": "-----BEGIN KEY-----\nMIIB\nabc=\n-----END KEY-----",
+		"B":   "2",
+	}
+	for k, v := range want {
+		if vals[k] != v {
+			t.Errorf("%s = %q, want %q", k, vals[k], v)
+		}
+	}
+}
+
+func TestLoadMultilineErrors(t *testing.T) {
+	long := strings.Repeat("x", maxLineSize/2)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"A=1\nB=\"open\nstill open\n", "env: line 2: unterminated quoted value for B"},
+		{"A=\"x\ny\nz\"\nmalformed\n", `env: line 4: malformed entry "malformed"`},
+		{"A=\"" + long + "\n" + long + "\n" + long + "\"\n", fmt.Sprintf("env: line 1: value for A is longer than %d bytes", maxLineSize)},
+	}
+
+	for _, tt := range tests {
+		name := writeFile(t, ".env", tt.data)
+		err := New(nil).LoadFileWith(name, LoadOptions{Strict: true})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("LoadFileWith error = %v, want %s", err, tt.want)
+		}
+	}
+}
+
+// Write files into a temporary directory, creating subdirectories as needed,
+// returning the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadIncludeCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.env": "A=1\ninclude b.env\n",
+		"b.env": "B=2\ninclude a.env\n",
+	})
+
+	err := New(nil).LoadFileWith(filepath.Join(dir, "a.env"), LoadOptions{Include: true})
+	if err == nil || !strings.Contains(err.Error(), "include cycle at "+filepath.Join(dir, "a.env")) {
+		t.Errorf("LoadFileWith error = %v, want an include cycle at a.env", err)
+	}
+}
+
+func TestLoadIncludeSubdirectory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.env":             "include config/base.env\ninclude \"config/my app.env\"\n",
+		"config/base.env":     "include shared.env\nBASE=1\n",
+		"config/shared.env":   "SHARED=2\n",
+		"config/my app.env":   "include 'shared.env'\nAPP=3\n",
+		"config/repeated.env": "include shared.env\ninclude shared.env\n",
+	})
+
+	e := New(nil)
+	if err := e.LoadFileWith(filepath.Join(dir, "app.env"), LoadOptions{Include: true}); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{"BASE": "1", "SHARED": "2", "APP": "3"} {
+		if got := e.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	// The same file may be included more than once outside a cycle.
+	if err := e.LoadFileWith(filepath.Join(dir, "config/repeated.env"), LoadOptions{Include: true}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,110 @@
+package env
+
+import (
+	"sync/atomic"
+)
+
+// Register a callback to be invoked with the old and new values whenever
+// the given environment variable changes through this package, whether by
+// Set, Unset, Clear, Restore, With, a Batch or the functions built on them
+// (such as LoadFile). An unset variable has the value "" (empty string), and
+// callbacks are not invoked when a value is replaced by the same value.
+// Changes made outside of this package, such as by calling os.Setenv
+// directly, are not observed until Sync is called.
+func OnChange(key string, fn func(old, new string)) {
+	std.OnChange(key, fn)
+}
+
+// Register a callback invoked when a variable changes, see OnChange.
+func (e *Env) OnChange(key string, fn func(old, new string)) {
+	e.observersMu.Lock()
+	defer e.observersMu.Unlock()
+
+	if e.observers == nil {
+		e.observers = map[string][]func(old, new string){}
+	}
+	e.observers[key] = append(e.observers[key], fn)
+}
+
+// Detect changes made to the environment outside of this package, such as by
+// os.Setenv or cgo, invoking OnChange callbacks for each variable whose value
+// differs from when Sync was last called. To do so the package keeps a shadow
+// copy of every variable from the first call to Sync, which only records the
+// environment without invoking callbacks, and updates it on each change made
+// through the package so those are not reported twice. Call Sync periodically
+// to poll for changes.
+func Sync() {
+	std.Sync()
+}
+
+// Detect changes made to the environment outside of this package, see Sync.
+func (e *Env) Sync() {
+	e.mu.Lock()
+	current, prior := e.environ(), e.shadow
+	e.shadow = e.environ()
+	e.mu.Unlock()
+
+	if prior == nil {
+		return
+	}
+
+	for k, v := range current {
+		if old := prior[k]; old != v {
+			e.notify(k, old, v)
+		}
+	}
+
+	for k, old := range prior {
+		if _, ok := current[k]; !ok && old != EmptyString {
+			e.notify(k, old, EmptyString)
+		}
+	}
+}
+
+// Create an accessor for an environment variable as an int (see GetIntOr)
+// that parses it only when first called and after each change, for hot paths
+// that read the same variable repeatedly. The cached value is invalidated by
+// changes made through this package, but not by those made directly with
+// os.Setenv and friends unless Sync is called afterwards.
+func NewCachedInt(key string, alt int) func() int {
+	return std.NewCachedInt(key, alt)
+}
+
+// Create a caching accessor for a variable as an int, see NewCachedInt.
+func (e *Env) NewCachedInt(key string, alt int) func() int {
+	type entry struct {
+		gen   uint64
+		value int
+	}
+
+	var gen atomic.Uint64
+	var cache atomic.Pointer[entry]
+
+	e.OnChange(key, func(old, new string) {
+		gen.Add(1)
+	})
+
+	return func() int {
+		g := gen.Load()
+		if c := cache.Load(); c != nil && c.gen == g {
+			return c.value
+		}
+
+		c := &entry{gen: g, value: e.GetIntOr(key, alt)}
+		cache.Store(c)
+
+		return c.value
+	}
+}
+
+// Invoke the callbacks registered for key. Must not be called with e.mu held,
+// so that callbacks may themselves use e.
+func (e *Env) notify(key, old, new string) {
+	e.observersMu.RLock()
+	fns := e.observers[key]
+	e.observersMu.RUnlock()
+
+	for _, fn := range fns {
+		fn(old, new)
+	}
+}
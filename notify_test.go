@@ -0,0 +1,118 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestNewCachedIntSet(t *testing.T) {
+	e := New(map[string]string{"N": "1"})
+	get := e.NewCachedInt("N", 7)
+
+	if v := get(); v != 1 {
+		t.Fatalf("got %d, want 1", v)
+	}
+
+	e.Set("N", "2")
+	if v := get(); v != 2 {
+		t.Fatalf("after Set got %d, want 2", v)
+	}
+
+	e.Unset("N")
+	if v := get(); v != 7 {
+		t.Fatalf("after Unset got %d, want 7", v)
+	}
+}
+
+func TestNewCachedIntWith(t *testing.T) {
+	e := New(map[string]string{"N": "1"})
+	get := e.NewCachedInt("N", 7)
+	get()
+
+	e.With("N", "2", func() {
+		if v := get(); v != 2 {
+			t.Fatalf("within With got %d, want 2", v)
+		}
+	})
+
+	if v := get(); v != 1 {
+		t.Fatalf("after With got %d, want 1", v)
+	}
+}
+
+func TestNewCachedIntRestore(t *testing.T) {
+	e := New(map[string]string{"N": "1"})
+	get := e.NewCachedInt("N", 7)
+	snap := e.Snapshot()
+
+	e.Set("N", "3")
+	if v := get(); v != 3 {
+		t.Fatalf("got %d, want 3", v)
+	}
+
+	if err := e.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if v := get(); v != 1 {
+		t.Fatalf("after Restore got %d, want 1", v)
+	}
+}
+
+func TestNewCachedIntClear(t *testing.T) {
+	e := New(map[string]string{"N": "3"})
+	get := e.NewCachedInt("N", 7)
+	get()
+
+	e.Clear()
+	if v := get(); v != 7 {
+		t.Fatalf("after Clear got %d, want 7", v)
+	}
+}
+
+func TestOnChangeMutators(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(e *Env)
+		want []string
+	}{
+		{"Set", func(e *Env) { e.Set("A", "2") }, []string{"1>2"}},
+		{"SetSame", func(e *Env) { e.Set("A", "1") }, nil},
+		{"Unset", func(e *Env) { e.Unset("A") }, []string{"1>"}},
+		{"Clear", func(e *Env) { e.Clear() }, []string{"1>"}},
+		{"ClearPrefix", func(e *Env) { e.ClearPrefix("") }, []string{"1>"}},
+		{"With", func(e *Env) { e.With("A", "2", func() {}) }, []string{"1>2", "2>1"}},
+		{"Restore", func(e *Env) {
+			snap := e.Snapshot()
+			e.Set("A", "3")
+			e.Restore(snap)
+		}, []string{"1>3", "3>1"}},
+		{"RestoreUnset", func(e *Env) { e.Restore(map[string]string{}) }, []string{"1>"}},
+		{"Batch", func(e *Env) {
+			b := e.NewBatch()
+			b.Set("A", "2")
+			b.Commit()
+			b.Rollback()
+		}, []string{"1>2", "2>1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(map[string]string{"A": "1"})
+
+			var got []string
+			e.OnChange("A", func(old, new string) {
+				got = append(got, old+">"+new)
+			})
+
+			tt.fn(e)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Get an environment variable parsed by the given function, ignoring
+// surrounding whitespace, so that types the package does not support can be
+// read in the same way as those it does. Errors from parse are wrapped in a
+// *ParseError naming the key and value. Returns the zero value and no error
+// if unset.
+func GetParsed[T any](key string, parse func(string) (T, error)) (T, error) {
+	return GetParsedFrom(std, key, parse)
+}
+
+// Get a variable from the given environment parsed by the given function, see
+// GetParsed.
+func GetParsedFrom[T any](e *Env, key string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	return parseWith(key, strings.TrimSpace(e.Get(key)), wrapParse(parse), zero)
+}
+
+// Get an environment variable parsed by the given function, returning an
+// alternate value if it is unset or cannot be parsed. See GetParsed.
+func GetParsedOr[T any](key string, parse func(string) (T, error), alt T) T {
+	return GetParsedOrFrom(std, key, parse, alt)
+}
+
+// Get a variable from the given environment parsed by the given function,
+// returning an alternate value if it is unset or cannot be parsed. See
+// GetParsed.
+func GetParsedOrFrom[T any](e *Env, key string, parse func(string) (T, error), alt T) T {
+	result, _ := parseWith(key, strings.TrimSpace(e.Get(key)), wrapParse(parse), alt)
+	return result
+}
+
+// Parse the value of key with the given function, returning alt and no error
+// if it is empty, or alt and the error if it is invalid. The Or and OrErr
+// getters are built on this so that they treat values alike.
+func parseWith[T any](key, v string, parse func(key, v string) (T, error), alt T) (T, error) {
+	if v == EmptyString {
+		return alt, nil
+	}
+
+	result, err := parse(key, v)
+	if err != nil {
+		return alt, err
+	}
+
+	return result, nil
+}
+
+// Adapt a parse function to report its errors as a *ParseError naming the
+// key, value and type.
+func wrapParse[T any](parse func(string) (T, error)) func(key, v string) (T, error) {
+	return func(key, v string) (result T, err error) {
+		if result, err = parse(v); err != nil {
+			var zero T
+			return zero, &ParseError{Key: key, Value: v, Type: reflect.TypeOf((*T)(nil)).Elem().String(), Err: err}
+		}
+
+		return
+	}
+}
@@ -0,0 +1,42 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestGetParsed(t *testing.T) {
+	e := New(map[string]string{"N": " 42 ", "BAD": "x", "BLANK": "  "})
+
+	if n, err := GetParsedFrom(e, "N", strconv.Atoi); n != 42 || err != nil {
+		t.Errorf("GetParsedFrom(N) = %d, %v, want 42, nil", n, err)
+	}
+	if n, err := GetParsedFrom(e, "BLANK", strconv.Atoi); n != 0 || err != nil {
+		t.Errorf("GetParsedFrom(BLANK) = %d, %v, want 0, nil", n, err)
+	}
+	for _, key := range []string{"BAD", "BLANK", "MISSING"} {
+		if n := GetParsedOrFrom(e, key, strconv.Atoi, -1); n != -1 {
+			t.Errorf("GetParsedOrFrom(%s) = %d, want -1", key, n)
+		}
+	}
+}
+
+func TestGetParsedErrorType(t *testing.T) {
+	e := New(map[string]string{"K": "v"})
+	bad := errors.New("bad")
+
+	_, err := GetParsedFrom(e, "K", func(string) (fmt.Stringer, error) { return nil, bad })
+	if want := `env: key K value "v" is not a valid fmt.Stringer: bad`; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+	if !errors.Is(err, bad) {
+		t.Errorf("error %v does not wrap the parse error", err)
+	}
+
+	_, err = GetParsedFrom(e, "K", func(string) ([]int, error) { return nil, bad })
+	if want := `env: key K value "v" is not a valid []int: bad`; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
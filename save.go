@@ -0,0 +1,153 @@
+package env
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Patterns of keys redacted by Dump when none are given.
+var defaultRedactKeys = []string{"*SECRET*", "*PASSWORD*", "*TOKEN*", "*KEY*"}
+
+// Write every environment variable to a given filename in .env format, sorted
+// by key. Values are quoted where needed so that the file reads back with
+// LoadFile unchanged.
+func Save(name string) error {
+	return std.Save(name)
+}
+
+// Write every environment variable to a given filename in .env format, sorted
+// by key. Values are quoted where needed so that the file reads back with
+// LoadFile unchanged.
+func (e *Env) Save(name string) error {
+	vals := e.Snapshot()
+
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+
+	return save(name, vals, keys)
+}
+
+// Write the given environment variables to a given filename as Save does.
+// Variables that are unset are omitted.
+func SaveKeys(name string, keys ...string) error {
+	return std.SaveKeys(name, keys...)
+}
+
+// Write the given environment variables to a given filename as Save does.
+// Variables that are unset are omitted.
+func (e *Env) SaveKeys(name string, keys ...string) error {
+	return save(name, e.Snapshot(), keys)
+}
+
+// Write the given environment variables to a given filename in the order
+// given rather than sorted, quoting values as Save does. An empty string in
+// keys writes a blank line and one beginning with "#" writes it as a comment,
+// so that related variables can be grouped into a readable template:
+//
+//	env.SaveOrdered(".env", []string{"# Database", "DB_HOST", "DB_USER", "", "# Cache", "REDIS_URL"})
+//
+// Variables that are unset are omitted.
+func SaveOrdered(name string, keys []string) error {
+	return std.SaveOrdered(name, keys)
+}
+
+// Write the given environment variables to a given filename in order, see
+// SaveOrdered.
+func (e *Env) SaveOrdered(name string, keys []string) error {
+	return write(name, e.Snapshot(), keys, true)
+}
+
+// Describe the environment as sorted KEY=value lines suitable for logging,
+// with the values of keys matching any of the given patterns replaced by
+// "****". Patterns are exact names or globs as understood by path.Match, such
+// as "*_PASSWORD". If no patterns are given, keys containing SECRET, PASSWORD,
+// TOKEN or KEY are redacted.
+func Dump(redactKeys ...string) string {
+	return std.Dump(redactKeys...)
+}
+
+// Describe the environment with values redacted, see Dump.
+func (e *Env) Dump(redactKeys ...string) string {
+	if len(redactKeys) == 0 {
+		redactKeys = defaultRedactKeys
+	}
+
+	vals := e.Snapshot()
+
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := vals[k]
+		for _, pattern := range redactKeys {
+			if ok, _ := path.Match(pattern, k); ok {
+				v = "****"
+				break
+			}
+		}
+
+		b.WriteString(k + "=" + v + "\n")
+	}
+
+	return b.String()
+}
+
+// Write the values of keys in sorted order, skipping any not present in vals.
+func save(name string, vals map[string]string, keys []string) (err error) {
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+
+	return write(name, vals, keys, false)
+}
+
+// Write the values of keys in the given order, skipping any not present in
+// vals. If layout is true, empty keys are written as blank lines and keys
+// beginning with "#" as comments.
+func write(name string, vals map[string]string, keys []string, layout bool) (err error) {
+	var file *os.File
+
+	if file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+		return
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, k := range keys {
+		if layout && (k == EmptyString || strings.HasPrefix(k, "#")) {
+			w.WriteString(k + "\n")
+		} else if v, ok := vals[k]; ok {
+			w.WriteString(k + "=" + quote(v) + "\n")
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	return file.Close()
+}
+
+// Quote a value so that it is read back literally by the loader. Values made
+// up only of safe characters are left bare.
+func quote(value string) string {
+	special := func(r rune) bool {
+		return !(r == '_' || r == '-' || r == '.' || r == '/' || r == ':' || r == ',' || r == '@' || r == '+' || r == '=' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'))
+	}
+	if strings.IndexFunc(value, special) < 0 {
+		return value
+	}
+
+	r := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\n", "\\n", "\r", "\\r", "\t", "\\t")
+
+	return "\"" + r.Replace(value) + "\""
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	vals := map[string]string{
+		"PLAIN":     "value",
+		"EMPTY":     "",
+		"SPACES":    "  leading and trailing  ",
+		"DOUBLE":    `say "hi"`,
+		"SINGLE":    "it's",
+		"BOTH":      `'a' "b"`,
+		"BACKSLASH": `C:\path\n`,
+		"DOLLAR":    "$HOME ${USER} $$",
+		"NEWLINES":  "line one\nline two\r\n",
+		"TAB":       "a\tb",
+		"HASH":      "a #b",
+		"EQUALS":    "a=b",
+		"EXPORT":    "export X=1",
+		"COMMENT":   "# not a comment",
+		"TRAILING":  `ends in \`,
+	}
+
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := New(vals).Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := New(nil).ParseFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range vals {
+		if got[k] != v {
+			t.Errorf("%s read back as %q, want %q", k, got[k], v)
+		}
+	}
+	if len(got) != len(vals) {
+		t.Errorf("read back %d variables, want %d", len(got), len(vals))
+	}
+
+	e := New(nil)
+	if err := e.LoadFile(name); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range vals {
+		if got, _ := e.Lookup(k); got != v {
+			t.Errorf("%s loaded as %q, want %q", k, got, v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package env
+
+// A view of an environment in which every key is prefixed, created by Scope.
+type Scoped struct {
+	env    *Env
+	prefix string
+}
+
+// Create a view of the environment whose methods prepend prefix to every
+// key, so Scope("APP_").Get("PORT") reads APP_PORT.
+func Scope(prefix string) *Scoped {
+	return std.Scope(prefix)
+}
+
+// Create a view of this environment whose methods prepend prefix to every
+// key, see Scope.
+func (e *Env) Scope(prefix string) *Scoped {
+	return &Scoped{env: e, prefix: prefix}
+}
+
+// The prefix prepended to keys within this view.
+func (s *Scoped) Prefix() string {
+	return s.prefix
+}
+
+// Get a prefixed environment variable, returns "" (empty string) if unset.
+func (s *Scoped) Get(key string) string {
+	return s.env.Get(s.prefix + key)
+}
+
+// Get a prefixed environment variable if it exists, otherwise return an
+// alternate value.
+func (s *Scoped) GetOr(key, alt string) string {
+	return s.env.GetOr(s.prefix+key, alt)
+}
+
+// Sets a prefixed environment variable unconditionally.
+func (s *Scoped) Set(key, value string) error {
+	return s.env.Set(s.prefix+key, value)
+}
+
+// Determine if a prefixed environment variable is set to a non-empty value.
+func (s *Scoped) IsSet(key string) bool {
+	return s.env.IsSet(s.prefix + key)
+}
+
+// Unset a prefixed environment variable.
+func (s *Scoped) Unset(key string) error {
+	return s.env.Unset(s.prefix + key)
+}
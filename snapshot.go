@@ -0,0 +1,244 @@
+package env
+
+import (
+	"sort"
+)
+
+// A single environment variable and its value.
+type KV struct {
+	Key, Value string
+}
+
+// Capture every environment variable and its value, sorted by key.
+func All() []KV {
+	return std.All()
+}
+
+// Capture every environment variable and its value, sorted by key.
+func (e *Env) All() []KV {
+	vals := e.Snapshot()
+
+	all := make([]KV, 0, len(vals))
+	for k, v := range vals {
+		all = append(all, KV{Key: k, Value: v})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Key < all[j].Key })
+
+	return all
+}
+
+// Capture every environment variable and its value.
+func Snapshot() map[string]string {
+	return std.Snapshot()
+}
+
+// Capture every environment variable and its value.
+func (e *Env) Snapshot() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.environ()
+}
+
+// Call fn for each environment variable and its value, in no particular
+// order, stopping if fn returns false. The environment is read-locked for the
+// duration, so fn sees a consistent view but blocks writers until Range
+// returns, and must not itself modify the environment.
+func Range(fn func(key, value string) bool) {
+	std.Range(fn)
+}
+
+// Call fn for each environment variable and its value, see Range.
+func (e *Env) Range(fn func(key, value string) bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for k, v := range e.environ() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Restore the environment to a snapshot taken with Snapshot, setting each
+// variable it contains and unsetting any that were added since.
+func Restore(snap map[string]string) (err error) {
+	return std.Restore(snap)
+}
+
+// Restore the environment to a snapshot taken with Snapshot, setting each
+// variable it contains and unsetting any that were added since.
+func (e *Env) Restore(snap map[string]string) (err error) {
+	e.mu.Lock()
+	vals := e.environ()
+	keys := make([]string, 0, len(vals)+len(snap))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	for k := range snap {
+		keys = append(keys, k)
+	}
+
+	prior := e.capture(keys)
+	err = e.reset(snap)
+	changes := e.changes(prior)
+	e.mu.Unlock()
+
+	changes.notify(e)
+
+	return
+}
+
+// Set each variable in snap and unset any others. Callers must hold e.mu for
+// writing.
+func (e *Env) reset(snap map[string]string) (err error) {
+	if e.frozen {
+		return ErrFrozen
+	}
+
+	for k := range e.environ() {
+		if _, ok := snap[k]; !ok {
+			if err = e.unsetenv(k); err != nil {
+				return
+			}
+		}
+	}
+
+	for k, v := range snap {
+		if err = e.setenv(k, v); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Set an environment variable, call fn, then return the variable to its prior
+// state (including unset) even if fn panics.
+func With(key, value string, fn func()) error {
+	return std.With(key, value, fn)
+}
+
+// Set an environment variable, call fn, then return the variable to its prior
+// state (including unset) even if fn panics.
+func (e *Env) With(key, value string, fn func()) error {
+	return e.WithMap(map[string]string{key: value}, fn)
+}
+
+// Set several environment variables, call fn, then return each to its prior
+// state (including unset) even if fn panics.
+func WithMap(vals map[string]string, fn func()) (err error) {
+	return std.WithMap(vals, fn)
+}
+
+// Set several environment variables, call fn, then return each to its prior
+// state (including unset) even if fn panics.
+func (e *Env) WithMap(vals map[string]string, fn func()) (err error) {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+
+	e.mu.RLock()
+	frozen := e.frozen
+	prior := e.capture(keys)
+	e.mu.RUnlock()
+
+	if frozen {
+		return ErrFrozen
+	}
+
+	defer func() {
+		e.mu.Lock()
+		current := e.capture(keys)
+		rerr := e.restore(prior)
+		changes := e.changes(current)
+		e.mu.Unlock()
+
+		changes.notify(e)
+
+		if err == nil {
+			err = rerr
+		}
+	}()
+
+	if err = e.SetAll(vals); err != nil {
+		return
+	}
+
+	fn()
+
+	return
+}
+
+// The values of a set of variables, with nil for those that are unset.
+type state map[string]*string
+
+// Record the state of the given keys. Callers must hold e.mu.
+func (e *Env) capture(keys []string) state {
+	s := make(state, len(keys))
+	for _, k := range keys {
+		if v, ok := e.lookupvar(k); ok {
+			s[k] = &v
+		} else {
+			s[k] = nil
+		}
+	}
+
+	return s
+}
+
+// Return variables to a recorded state, returning the first error but
+// restoring as many as possible. Callers must hold e.mu for writing.
+func (e *Env) restore(s state) (err error) {
+	for k, v := range s {
+		var rerr error
+		if v != nil {
+			rerr = e.setenv(k, *v)
+		} else {
+			rerr = e.unsetenv(k)
+		}
+
+		if err == nil {
+			err = rerr
+		}
+	}
+
+	return
+}
+
+// Compare two sets of variables, such as snapshots taken with Snapshot.
+// Returns those only in b as added, those only in a as removed (with their
+// old values), and those in both with different values as changed (with their
+// new values).
+func Diff(a, b map[string]string) (added, removed, changed map[string]string) {
+	added, removed, changed = map[string]string{}, map[string]string{}, map[string]string{}
+
+	for k, v := range a {
+		if nv, ok := b[k]; !ok {
+			removed[k] = v
+		} else if nv != v {
+			changed[k] = nv
+		}
+	}
+
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			added[k] = v
+		}
+	}
+
+	return
+}
+
+// Compare a snapshot taken with Snapshot to the current environment, see
+// Diff.
+func DiffSnapshot(snap map[string]string) (added, removed, changed map[string]string) {
+	return std.DiffSnapshot(snap)
+}
+
+// Compare a snapshot taken with Snapshot to the current environment, see
+// Diff.
+func (e *Env) DiffSnapshot(snap map[string]string) (added, removed, changed map[string]string) {
+	return Diff(snap, e.Snapshot())
+}
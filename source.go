@@ -0,0 +1,111 @@
+package env
+
+import (
+	"fmt"
+	"os"
+)
+
+// A source of environment variable values, such as a secrets manager, that
+// can be layered above an environment with SetSources.
+type Source interface {
+	// Look up a variable, reporting whether it is set.
+	Lookup(key string) (string, bool)
+}
+
+// Implemented by sources that name themselves for GetWithSource.
+type NamedSource interface {
+	Source
+
+	// A short name for the source, such as "os" or "ssm".
+	Name() string
+}
+
+// The process environment as a Source.
+type OSSource struct{}
+
+// Look up a variable in the process environment.
+func (OSSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Name the source "os".
+func (OSSource) Name() string {
+	return "os"
+}
+
+// A fixed set of variables as a Source.
+type MapSource map[string]string
+
+// Look up a variable in the map.
+func (m MapSource) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Name the source "map".
+func (MapSource) Name() string {
+	return "map"
+}
+
+// Layer sources above the environment, so that Get, Lookup and everything
+// built on them consult each source in order before the environment's own
+// variables, which for the package level functions is the process
+// environment (as OSSource). The first source that has a variable wins, so
+// values from sources hide those set with Set. Keys, Snapshot, Range and
+// other functions that list variables only see the environment's own
+// variables, and changes are only ever made to them. Calling SetSources with
+// no arguments removes any sources.
+//
+// Sources are consulted with the environment locked, so must not use the
+// environment themselves.
+func SetSources(sources ...Source) {
+	std.SetSources(sources...)
+}
+
+// Layer sources above this environment, see SetSources.
+func (e *Env) SetSources(sources ...Source) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sources = append([]Source(nil), sources...)
+}
+
+// Look up an environment variable along with the name of the source that
+// supplied it, such as to debug which layer of configuration won. Sources
+// are named by their Name method if they have one (see NamedSource) or their
+// type otherwise, and the environment's own variables are named "os" for the
+// package level functions or "env" for an Env created with New.
+func GetWithSource(key string) (value, source string, ok bool) {
+	return std.GetWithSource(key)
+}
+
+// Look up a variable along with the name of its source, see GetWithSource.
+func (e *Env) GetWithSource(key string) (value, source string, ok bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, s := range e.sources {
+		if value, ok = s.Lookup(key); ok {
+			return value, sourceName(s), true
+		}
+	}
+
+	if value, ok = e.lookupvar(key); !ok {
+		return
+	}
+
+	if e.process {
+		return value, "os", true
+	}
+
+	return value, "env", true
+}
+
+// Name a source for GetWithSource.
+func sourceName(s Source) string {
+	if n, ok := s.(NamedSource); ok {
+		return n.Name()
+	}
+
+	return fmt.Sprintf("%T", s)
+}
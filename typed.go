@@ -0,0 +1,953 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Get an environment variable as an int. Returns 0 and no error if unset.
+func GetInt(key string) (int, error) {
+	return std.GetInt(key)
+}
+
+// Get an environment variable as an int. Returns 0 and no error if unset.
+func (e *Env) GetInt(key string) (int, error) {
+	return parseInt(key, e.Get(key))
+}
+
+// Parse the value of key as an int, returning 0 and no error if empty.
+func parseInt(key, v string) (result int, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = strconv.Atoi(v); err != nil {
+		result = 0
+		err = &ParseError{Key: key, Value: v, Type: "int", Err: numError(err)}
+	}
+
+	return
+}
+
+// Get an environment variable as an int, returning an alternate value if it is
+// unset or not a valid int.
+func GetIntOr(key string, alt int) int {
+	return std.GetIntOr(key, alt)
+}
+
+// Get an environment variable as an int, returning an alternate value if it is
+// unset or not a valid int.
+func (e *Env) GetIntOr(key string, alt int) int {
+	result, _ := parseWith(key, e.Get(key), parseInt, alt)
+	return result
+}
+
+// Get an environment variable as an int, returning an alternate value
+// if it is unset. A value that is set but not a valid int is reported as an
+// error (along with the alternate value) rather than ignored.
+func GetIntOrErr(key string, alt int) (int, error) {
+	return std.GetIntOrErr(key, alt)
+}
+
+// Get an environment variable as an int, returning an alternate value
+// if it is unset. A value that is set but not a valid int is reported as an
+// error (along with the alternate value) rather than ignored.
+func (e *Env) GetIntOrErr(key string, alt int) (int, error) {
+	return parseWith(key, e.Get(key), parseInt, alt)
+}
+
+// Get an environment variable as an int64. Returns 0 and no error if unset.
+func GetInt64(key string) (result int64, err error) {
+	return std.GetInt64(key)
+}
+
+// Get an environment variable as an int64. Returns 0 and no error if unset.
+func (e *Env) GetInt64(key string) (int64, error) {
+	return parseInt64(key, e.Get(key))
+}
+
+// Parse the value of key as an int64, returning 0 and no error if empty.
+func parseInt64(key, v string) (result int64, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = strconv.ParseInt(v, 10, 64); err != nil {
+		result = 0
+		err = &ParseError{Key: key, Value: v, Type: "int64", Err: numError(err)}
+	}
+
+	return
+}
+
+// Get an environment variable as an int64, returning an alternate value if it
+// is unset or not a valid int64.
+func GetInt64Or(key string, alt int64) int64 {
+	return std.GetInt64Or(key, alt)
+}
+
+// Get an environment variable as an int64, returning an alternate value if it
+// is unset or not a valid int64.
+func (e *Env) GetInt64Or(key string, alt int64) int64 {
+	result, _ := parseWith(key, e.Get(key), parseInt64, alt)
+	return result
+}
+
+// Get an environment variable as a uint64. Returns 0 and no error if unset.
+func GetUint(key string) (result uint64, err error) {
+	return std.GetUint(key)
+}
+
+// Get an environment variable as a uint64. Returns 0 and no error if unset.
+func (e *Env) GetUint(key string) (uint64, error) {
+	return parseUint(key, e.Get(key))
+}
+
+// Parse the value of key as a uint64, returning 0 and no error if empty.
+func parseUint(key, v string) (result uint64, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = strconv.ParseUint(v, 10, 64); err != nil {
+		result = 0
+		err = &ParseError{Key: key, Value: v, Type: "uint", Err: numError(err)}
+	}
+
+	return
+}
+
+// Get an environment variable as a uint64, returning an alternate value if it
+// is unset or not a valid uint.
+func GetUintOr(key string, alt uint64) uint64 {
+	return std.GetUintOr(key, alt)
+}
+
+// Get an environment variable as a uint64, returning an alternate value if it
+// is unset or not a valid uint.
+func (e *Env) GetUintOr(key string, alt uint64) uint64 {
+	result, _ := parseWith(key, e.Get(key), parseUint, alt)
+	return result
+}
+
+// Multipliers for byte size suffixes, see GetBytes.
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// Get an environment variable as a number of bytes, such as "512", "10KB" or
+// "4MiB". Decimal suffixes (KB, MB, GB, TB, PB) are multiples of 1000 and
+// binary suffixes (KiB, MiB, GiB, TiB, PiB) multiples of 1024. Suffixes are
+// case-insensitive and may follow a fractional number such as "1.5GB", as
+// long as it comes to a whole number of bytes. Returns 0 and no error if
+// unset.
+func GetBytes(key string) (result int64, err error) {
+	return std.GetBytes(key)
+}
+
+// Get a variable as a number of bytes, see GetBytes.
+func (e *Env) GetBytes(key string) (result int64, err error) {
+	v := strings.TrimSpace(e.Get(key))
+	if v == EmptyString {
+		return
+	}
+
+	i := strings.LastIndexAny(v, "0123456789.") + 1
+	num, unit := v[:i], strings.ToLower(strings.TrimSpace(v[i:]))
+
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, &ParseError{Key: key, Value: v, Type: "byte size", Err: fmt.Errorf("unknown size suffix %q", v[i:])}
+	}
+
+	// The float bounds the size before it is computed exactly, so that large
+	// whole numbers keep their precision and fractions of a byte are reported
+	// rather than truncated.
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 || f*float64(mult) > math.MaxInt64 {
+		return 0, &ParseError{Key: key, Value: v, Type: "byte size"}
+	}
+
+	n, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return 0, &ParseError{Key: key, Value: v, Type: "byte size"}
+	}
+
+	if n.Mul(n, new(big.Rat).SetInt64(mult)); !n.IsInt() {
+		return 0, &ParseError{Key: key, Value: v, Type: "byte size", Err: errors.New("not a whole number of bytes")}
+	} else if !n.Num().IsInt64() {
+		return 0, &ParseError{Key: key, Value: v, Type: "byte size"}
+	}
+
+	return n.Num().Int64(), nil
+}
+
+// Get an environment variable as a bool. Accepts 1/0, true/false, yes/no and
+// on/off in any case. Returns false and no error if unset.
+func GetBool(key string) (bool, error) {
+	return std.GetBool(key)
+}
+
+// Get an environment variable as a bool. Accepts 1/0, true/false, yes/no and
+// on/off in any case. Returns false and no error if unset.
+func (e *Env) GetBool(key string) (bool, error) {
+	return parseBool(key, e.Get(key))
+}
+
+// Parse the value of key as a bool, returning false and no error if empty.
+func parseBool(key, v string) (result bool, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "on":
+		result = true
+	case "0", "false", "no", "off":
+		result = false
+	default:
+		err = &ParseError{Key: key, Value: v, Type: "bool"}
+	}
+
+	return
+}
+
+// Get an environment variable as a bool, returning an alternate value if it is
+// unset or not a valid bool.
+func GetBoolOr(key string, alt bool) bool {
+	return std.GetBoolOr(key, alt)
+}
+
+// Get an environment variable as a bool, returning an alternate value if it is
+// unset or not a valid bool.
+func (e *Env) GetBoolOr(key string, alt bool) bool {
+	result, _ := parseWith(key, e.Get(key), parseBool, alt)
+	return result
+}
+
+// Get an environment variable as a bool, returning an alternate value
+// if it is unset. A value that is set but not a valid bool is reported as an
+// error (along with the alternate value) rather than ignored.
+func GetBoolOrErr(key string, alt bool) (bool, error) {
+	return std.GetBoolOrErr(key, alt)
+}
+
+// Get an environment variable as a bool, returning an alternate value
+// if it is unset. A value that is set but not a valid bool is reported as an
+// error (along with the alternate value) rather than ignored.
+func (e *Env) GetBoolOrErr(key string, alt bool) (bool, error) {
+	return parseWith(key, e.Get(key), parseBool, alt)
+}
+
+// Get an environment variable as a float64, ignoring surrounding whitespace.
+// Returns 0 and no error if unset.
+func GetFloat(key string) (float64, error) {
+	return std.GetFloat(key)
+}
+
+// Get an environment variable as a float64, ignoring surrounding whitespace.
+// Returns 0 and no error if unset.
+func (e *Env) GetFloat(key string) (float64, error) {
+	return parseFloat(key, e.Get(key))
+}
+
+// Get an environment variable as a float64, returning an alternate value
+// if it is unset. A value that is set but not a valid float is reported as an
+// error (along with the alternate value) rather than ignored.
+func GetFloatOrErr(key string, alt float64) (float64, error) {
+	return std.GetFloatOrErr(key, alt)
+}
+
+// Get an environment variable as a float64, returning an alternate value
+// if it is unset. A value that is set but not a valid float is reported as an
+// error (along with the alternate value) rather than ignored.
+func (e *Env) GetFloatOrErr(key string, alt float64) (float64, error) {
+	return parseWith(key, strings.TrimSpace(e.Get(key)), parseFloat, alt)
+}
+
+// Get an environment variable as a fraction from 0 to 1, written either as a
+// percentage such as "25%" or as a fraction such as "0.25" (both giving 0.25).
+// Values outside 0-100% are an error rather than clamped. Returns 0 and no
+// error if unset.
+func GetPercent(key string) (float64, error) {
+	return std.GetPercent(key)
+}
+
+// Get an environment variable as a fraction from 0 to 1, see GetPercent.
+func (e *Env) GetPercent(key string) (result float64, err error) {
+	v := strings.TrimSpace(e.Get(key))
+	if v == EmptyString {
+		return
+	}
+
+	num, percent := strings.CutSuffix(v, "%")
+	if result, err = strconv.ParseFloat(strings.TrimSpace(num), 64); err != nil {
+		return 0, &ParseError{Key: key, Value: v, Type: "percentage", Err: numError(err)}
+	}
+
+	if percent {
+		result /= 100
+	}
+
+	if result < 0 || result > 1 || math.IsNaN(result) {
+		msg := "must be from 0% to 100%"
+		if !percent {
+			// A bare number is a fraction, so suggest the percentage that may
+			// have been meant.
+			msg = "must be a fraction from 0 to 1"
+			if result > 1 && result <= 100 {
+				msg += fmt.Sprintf(", or a percentage such as %q", v+"%")
+			}
+		}
+		return 0, &ParseError{Key: key, Value: v, Type: "percentage", Err: errors.New(msg)}
+	}
+
+	return
+}
+
+// Parse the value of key as a float64, returning 0 and no error if empty.
+func parseFloat(key, v string) (result float64, err error) {
+	if v = strings.TrimSpace(v); v == EmptyString {
+		return
+	}
+
+	if result, err = strconv.ParseFloat(v, 64); err != nil {
+		result = 0
+		err = &ParseError{Key: key, Value: v, Type: "float", Err: numError(err)}
+	}
+
+	return
+}
+
+// Get an environment variable as a time.Duration (such as "30s" or "1h30m").
+// Returns 0 and no error if unset.
+func GetDuration(key string) (time.Duration, error) {
+	return std.GetDuration(key)
+}
+
+// Get an environment variable as a time.Duration (such as "30s" or "1h30m").
+// Returns 0 and no error if unset.
+func (e *Env) GetDuration(key string) (time.Duration, error) {
+	return parseDuration(key, e.Get(key))
+}
+
+// Parse the value of key as a time.Duration, returning 0 and no error if empty.
+func parseDuration(key, v string) (result time.Duration, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = time.ParseDuration(v); err != nil {
+		err = &ParseError{Key: key, Value: v, Type: "duration"}
+	}
+
+	return
+}
+
+// Get an environment variable as a time.Duration, returning an alternate value
+// if it is unset or not a valid duration.
+func GetDurationOr(key string, alt time.Duration) time.Duration {
+	return std.GetDurationOr(key, alt)
+}
+
+// Get an environment variable as a time.Duration, returning an alternate value
+// if it is unset or not a valid duration.
+func (e *Env) GetDurationOr(key string, alt time.Duration) time.Duration {
+	result, _ := parseWith(key, e.Get(key), parseDuration, alt)
+	return result
+}
+
+// Get an environment variable as a time.Duration, returning an alternate value
+// if it is unset. A value that is set but not a valid duration is reported as an
+// error (along with the alternate value) rather than ignored.
+func GetDurationOrErr(key string, alt time.Duration) (time.Duration, error) {
+	return std.GetDurationOrErr(key, alt)
+}
+
+// Get an environment variable as a time.Duration, returning an alternate value
+// if it is unset. A value that is set but not a valid duration is reported as an
+// error (along with the alternate value) rather than ignored.
+func (e *Env) GetDurationOrErr(key string, alt time.Duration) (time.Duration, error) {
+	return parseWith(key, e.Get(key), parseDuration, alt)
+}
+
+// Get an environment variable as a time.Time parsed with the given layout (see
+// time.Parse). Returns the zero time and no error if unset.
+func GetTime(key, layout string) (time.Time, error) {
+	return std.GetTime(key, layout)
+}
+
+// Get an environment variable as a time.Time parsed with the given layout (see
+// time.Parse). Returns the zero time and no error if unset.
+func (e *Env) GetTime(key, layout string) (result time.Time, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = time.Parse(layout, v); err != nil {
+		err = &ParseError{Key: key, Value: v, Type: fmt.Sprintf("time in layout %q", layout), Err: err}
+	}
+
+	return
+}
+
+// Get an environment variable as a time.Time in RFC 3339 format, such as
+// "2024-01-02T15:04:05Z". Returns the zero time and no error if unset.
+func GetTimeRFC3339(key string) (time.Time, error) {
+	return std.GetTimeRFC3339(key)
+}
+
+// Get an environment variable as a time.Time in RFC 3339 format, such as
+// "2024-01-02T15:04:05Z". Returns the zero time and no error if unset.
+func (e *Env) GetTimeRFC3339(key string) (time.Time, error) {
+	return e.GetTime(key, time.RFC3339)
+}
+
+// Get an environment variable as a comma-separated list. Elements are trimmed
+// of surrounding whitespace and empty elements are dropped. Returns an empty
+// slice if unset.
+func GetSlice(key string) []string {
+	return std.GetSlice(key)
+}
+
+// Get an environment variable as a comma-separated list. Elements are trimmed
+// of surrounding whitespace and empty elements are dropped. Returns an empty
+// slice if unset.
+func (e *Env) GetSlice(key string) []string {
+	return e.GetSliceSep(key, ",")
+}
+
+// Get an environment variable as a list split on the given separator, such as
+// ":" for PATH-style values. See GetSlice.
+func GetSliceSep(key, sep string) []string {
+	return std.GetSliceSep(key, sep)
+}
+
+// Get an environment variable as a list split on the given separator, such as
+// ":" for PATH-style values. See GetSlice.
+func (e *Env) GetSliceSep(key, sep string) []string {
+	result := []string{}
+
+	for _, s := range strings.Split(e.Get(key), sep) {
+		if s = strings.TrimSpace(s); s != EmptyString {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// Get an environment variable as a comma-separated list of ints, such as
+// "1,2,4,8". Returns an empty slice if unset, or an error naming the first
+// element that is not a valid int.
+func GetIntSlice(key string) ([]int, error) {
+	return std.GetIntSlice(key)
+}
+
+// Get an environment variable as a comma-separated list of ints, such as
+// "1,2,4,8". Returns an empty slice if unset, or an error naming the first
+// element that is not a valid int.
+func (e *Env) GetIntSlice(key string) (result []int, err error) {
+	result = []int{}
+
+	for i, s := range e.GetSlice(key) {
+		var n int
+		if n, err = strconv.Atoi(s); err != nil {
+			return []int{}, &ParseError{Key: key, Value: e.Get(key), Type: "int list", Err: fmt.Errorf("element %d %q: %w", i, s, numError(err))}
+		}
+		result = append(result, n)
+	}
+
+	return
+}
+
+// Get an environment variable as a comma-separated list of floats, such as
+// "0.5,0.25,0.25". Returns an empty slice if unset, or an error naming the
+// first element that is not a valid float.
+func GetFloatSlice(key string) ([]float64, error) {
+	return std.GetFloatSlice(key)
+}
+
+// Get an environment variable as a comma-separated list of floats, such as
+// "0.5,0.25,0.25". Returns an empty slice if unset, or an error naming the
+// first element that is not a valid float.
+func (e *Env) GetFloatSlice(key string) (result []float64, err error) {
+	result = []float64{}
+
+	for i, s := range e.GetSlice(key) {
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); err != nil {
+			return []float64{}, &ParseError{Key: key, Value: e.Get(key), Type: "float list", Err: fmt.Errorf("element %d %q: %w", i, s, numError(err))}
+		}
+		result = append(result, f)
+	}
+
+	return
+}
+
+// Get an environment variable as a comma-separated list of durations, such as
+// "100ms,500ms,2s". Returns an empty slice if unset, or an error naming the
+// first element that is not a valid duration.
+func GetDurationSlice(key string) ([]time.Duration, error) {
+	return std.GetDurationSlice(key)
+}
+
+// Get an environment variable as a comma-separated list of durations, such as
+// "100ms,500ms,2s". Returns an empty slice if unset, or an error naming the
+// first element that is not a valid duration.
+func (e *Env) GetDurationSlice(key string) (result []time.Duration, err error) {
+	result = []time.Duration{}
+
+	for i, s := range e.GetSlice(key) {
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err != nil {
+			return []time.Duration{}, &ParseError{Key: key, Value: e.Get(key), Type: "duration list", Err: fmt.Errorf("element %d %q: %w", i, s, err)}
+		}
+		result = append(result, d)
+	}
+
+	return
+}
+
+// Get an environment variable holding CSV, such as a small table with one
+// row per line, as records parsed by encoding/csv. Quoted fields may contain
+// commas and newlines, and rows may have differing numbers of fields. Returns
+// nil and no error if unset.
+func GetCSVRecords(key string) ([][]string, error) {
+	return std.GetCSVRecords(key)
+}
+
+// Get an environment variable holding CSV as records, see GetCSVRecords.
+func (e *Env) GetCSVRecords(key string) (result [][]string, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return
+	}
+
+	r := csv.NewReader(strings.NewReader(v))
+	r.FieldsPerRecord = -1
+
+	if result, err = r.ReadAll(); err != nil {
+		result = nil
+		err = &ParseError{Key: key, Value: v, Type: "CSV value", Err: err}
+	}
+
+	return
+}
+
+// Get an environment variable holding a single character, such as a
+// delimiter, as a rune. Returns 0 and no error if unset, or an error if it is
+// set to anything other than exactly one character (including "").
+func GetRune(key string) (rune, error) {
+	return std.GetRune(key)
+}
+
+// Get an environment variable holding a single character, such as a
+// delimiter, as a rune. Returns 0 and no error if unset, or an error if it is
+// set to anything other than exactly one character (including "").
+func (e *Env) GetRune(key string) (rune, error) {
+	v, ok := e.Lookup(key)
+	if !ok {
+		return 0, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(v)
+	if size == 0 || size != len(v) || r == utf8.RuneError {
+		return 0, &ParseError{Key: key, Value: v, Type: "rune", Err: errors.New("must be exactly one character")}
+	}
+
+	return r, nil
+}
+
+// Get an environment variable that must match one of the allowed values
+// exactly (case-sensitively). Returns an error if it is unset or does not
+// match.
+func GetEnum(key string, allowed ...string) (string, error) {
+	return std.GetEnum(key, allowed...)
+}
+
+// Get an environment variable that must match one of the allowed values
+// exactly (case-sensitively). Returns an error if it is unset or does not
+// match.
+func (e *Env) GetEnum(key string, allowed ...string) (string, error) {
+	v, err := e.GetRequired(key)
+	if err != nil {
+		return EmptyString, err
+	}
+
+	for _, a := range allowed {
+		if v == a {
+			return v, nil
+		}
+	}
+
+	return EmptyString, &ParseError{Key: key, Value: v, Type: "value", Err: fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))}
+}
+
+// Get an environment variable that must match one of the allowed values as
+// GetEnum does, returning an alternate value if it is unset or does not match.
+func GetEnumOr(key, alt string, allowed ...string) string {
+	return std.GetEnumOr(key, alt, allowed...)
+}
+
+// Get an environment variable that must match one of the allowed values as
+// GetEnum does, returning an alternate value if it is unset or does not match.
+func (e *Env) GetEnumOr(key, alt string, allowed ...string) string {
+	if v, err := e.GetEnum(key, allowed...); err == nil {
+		return v
+	}
+
+	return alt
+}
+
+// Get an environment variable as an absolute URL, which must have both a
+// scheme and a host (so "localhost:5432" is rejected). Returns nil and no
+// error if unset.
+func GetURL(key string) (result *url.URL, err error) {
+	return std.GetURL(key)
+}
+
+// Get an environment variable as an absolute URL, which must have both a
+// scheme and a host (so "localhost:5432" is rejected). Returns nil and no
+// error if unset.
+func (e *Env) GetURL(key string) (*url.URL, error) {
+	return parseURL(key, e.Get(key))
+}
+
+// Parse the value of key as an absolute URL, returning nil and no error if
+// empty.
+func parseURL(key, v string) (result *url.URL, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = url.Parse(v); err != nil {
+		return nil, &ParseError{Key: key, Value: v, Type: "URL", Err: err}
+	}
+
+	if result.Scheme == EmptyString || result.Host == EmptyString {
+		return nil, &ParseError{Key: key, Value: v, Type: "URL", Err: errors.New("missing scheme or host")}
+	}
+
+	return
+}
+
+// Get an environment variable as an IP address, such as "10.0.0.1" or "::1".
+// Returns nil and no error if unset.
+func GetIP(key string) (net.IP, error) {
+	return std.GetIP(key)
+}
+
+// Get an environment variable as an IP address, such as "10.0.0.1" or "::1".
+// Returns nil and no error if unset.
+func (e *Env) GetIP(key string) (result net.IP, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return
+	}
+
+	if result = net.ParseIP(v); result == nil {
+		err = &ParseError{Key: key, Value: v, Type: "IP address"}
+	}
+
+	return
+}
+
+// Get an environment variable as a network in CIDR notation, such as
+// "10.0.0.0/8". Returns nil and no error if unset.
+func GetCIDR(key string) (*net.IPNet, error) {
+	return std.GetCIDR(key)
+}
+
+// Get an environment variable as a network in CIDR notation, such as
+// "10.0.0.0/8". Returns nil and no error if unset.
+func (e *Env) GetCIDR(key string) (result *net.IPNet, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return
+	}
+
+	if _, result, err = net.ParseCIDR(v); err != nil {
+		err = &ParseError{Key: key, Value: v, Type: "CIDR"}
+	}
+
+	return
+}
+
+// Get an environment variable holding a path that exists on disk, such as a
+// file or directory. Returns a *MissingError if unset, or an error if the
+// path does not exist.
+func GetPath(key string) (string, error) {
+	return std.GetPath(key)
+}
+
+// Get an environment variable holding a path that exists on disk, such as a
+// file or directory. Returns a *MissingError if unset, or an error if the
+// path does not exist.
+func (e *Env) GetPath(key string) (string, error) {
+	v, _, err := e.stat(key)
+	return v, err
+}
+
+// Get an environment variable holding the path of a directory. Returns a
+// *MissingError if unset, or an error if the path does not exist or is not a
+// directory.
+func GetPathDir(key string) (string, error) {
+	return std.GetPathDir(key)
+}
+
+// Get an environment variable holding the path of a directory. Returns a
+// *MissingError if unset, or an error if the path does not exist or is not a
+// directory.
+func (e *Env) GetPathDir(key string) (string, error) {
+	v, info, err := e.stat(key)
+	if err == nil && !info.IsDir() {
+		return EmptyString, fmt.Errorf("env: key %s path %q is not a directory", key, v)
+	}
+
+	return v, err
+}
+
+// Get an environment variable holding the path of a regular file. Returns a
+// *MissingError if unset, or an error if the path does not exist or is not a
+// regular file.
+func GetPathFile(key string) (string, error) {
+	return std.GetPathFile(key)
+}
+
+// Get an environment variable holding the path of a regular file. Returns a
+// *MissingError if unset, or an error if the path does not exist or is not a
+// regular file.
+func (e *Env) GetPathFile(key string) (string, error) {
+	v, info, err := e.stat(key)
+	if err == nil && !info.Mode().IsRegular() {
+		return EmptyString, fmt.Errorf("env: key %s path %q is not a regular file", key, v)
+	}
+
+	return v, err
+}
+
+// Get an environment variable holding a path along with the path's file info.
+func (e *Env) stat(key string) (v string, info fs.FileInfo, err error) {
+	if v, err = e.GetRequired(key); err != nil {
+		return
+	}
+
+	if info, err = os.Stat(v); errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("env: key %s path %q does not exist", key, v)
+	} else if err != nil {
+		err = fmt.Errorf("env: key %s path %q: %w", key, v, err)
+	}
+
+	if err != nil {
+		v = EmptyString
+	}
+
+	return
+}
+
+// Decode an environment variable holding JSON into the value pointed to by v.
+// If the variable is unset v is left unchanged and no error is returned.
+func GetJSON(key string, v interface{}) (err error) {
+	return std.GetJSON(key, v)
+}
+
+// Decode an environment variable holding JSON into the value pointed to by v.
+// If the variable is unset v is left unchanged and no error is returned.
+func (e *Env) GetJSON(key string, v interface{}) (err error) {
+	s := e.Get(key)
+	if s == EmptyString {
+		return
+	}
+
+	if err = json.Unmarshal([]byte(s), v); err != nil {
+		err = &ParseError{Key: key, Value: s, Type: "JSON value", Err: err}
+	}
+
+	return
+}
+
+// Get an environment variable holding a JSON object of strings, such as
+// {"a":"b"}, as a map. Any other JSON value, including null, is reported as a
+// *ParseError. Returns an empty map and no error if unset.
+func GetStringMap(key string) (map[string]string, error) {
+	return std.GetStringMap(key)
+}
+
+// Get an environment variable holding a JSON object of strings, such as
+// {"a":"b"}, as a map. Any other JSON value, including null, is reported as a
+// *ParseError. Returns an empty map and no error if unset.
+func (e *Env) GetStringMap(key string) (result map[string]string, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return map[string]string{}, nil
+	}
+
+	if err = json.Unmarshal([]byte(v), &result); err != nil {
+		return nil, &ParseError{Key: key, Value: v, Type: "JSON value", Err: err}
+	}
+
+	// JSON null leaves the map nil rather than failing to unmarshal.
+	if result == nil {
+		return nil, &ParseError{Key: key, Value: v, Type: "JSON object"}
+	}
+
+	return
+}
+
+// Get an environment variable holding base64 in the standard encoding (RFC
+// 4648), decoded to bytes. The value must be padded with "=" to a multiple of
+// four characters. Returns nil and no error if unset.
+func GetBase64(key string) ([]byte, error) {
+	return std.GetBase64(key)
+}
+
+// Get an environment variable holding base64 in the standard encoding (RFC
+// 4648), decoded to bytes. The value must be padded with "=" to a multiple of
+// four characters. Returns nil and no error if unset.
+func (e *Env) GetBase64(key string) ([]byte, error) {
+	return decodeBase64(key, e.Get(key), base64.StdEncoding)
+}
+
+// Get an environment variable holding base64 in the URL-safe encoding (RFC
+// 4648), which uses "-" and "_" in place of "+" and "/", decoded to bytes.
+// Padding is optional. Returns nil and no error if unset.
+func GetBase64URL(key string) ([]byte, error) {
+	return std.GetBase64URL(key)
+}
+
+// Get an environment variable holding base64 in the URL-safe encoding (RFC
+// 4648), which uses "-" and "_" in place of "+" and "/", decoded to bytes.
+// Padding is optional. Returns nil and no error if unset.
+func (e *Env) GetBase64URL(key string) ([]byte, error) {
+	return decodeBase64(key, strings.TrimRight(e.Get(key), "="), base64.RawURLEncoding)
+}
+
+// Get an environment variable holding hex-encoded bytes, such as an HMAC key,
+// decoded to bytes. Upper and lower case digits are accepted. Returns nil and
+// no error if unset.
+func GetHex(key string) ([]byte, error) {
+	return std.GetHex(key)
+}
+
+// Get an environment variable holding hex-encoded bytes, such as an HMAC key,
+// decoded to bytes. Upper and lower case digits are accepted. Returns nil and
+// no error if unset.
+func (e *Env) GetHex(key string) (result []byte, err error) {
+	v := e.Get(key)
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = hex.DecodeString(v); err != nil {
+		result = nil
+		err = &ParseError{Key: key, Value: v, Type: "hex value", Err: err}
+	}
+
+	return
+}
+
+// Decode the value of key with the given encoding, returning nil and no error
+// if empty.
+func decodeBase64(key, v string, enc *base64.Encoding) (result []byte, err error) {
+	if v == EmptyString {
+		return
+	}
+
+	if result, err = enc.DecodeString(v); err != nil {
+		result = nil
+		err = &ParseError{Key: key, Value: v, Type: "base64 value", Err: err}
+	}
+
+	return
+}
+
+// Get an environment variable as an int or panic if it is unset or invalid.
+func MustGetInt(key string) int {
+	return std.MustGetInt(key)
+}
+
+// Get an environment variable as an int or panic if it is unset or invalid.
+func (e *Env) MustGetInt(key string) int {
+	result, err := parseInt(key, e.MustGet(key))
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// Get an environment variable as a bool or panic if it is unset or invalid.
+func MustGetBool(key string) bool {
+	return std.MustGetBool(key)
+}
+
+// Get an environment variable as a bool or panic if it is unset or invalid.
+func (e *Env) MustGetBool(key string) bool {
+	result, err := parseBool(key, e.MustGet(key))
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// Get an environment variable as a time.Duration or panic if it is unset or
+// invalid.
+func MustGetDuration(key string) time.Duration {
+	return std.MustGetDuration(key)
+}
+
+// Get an environment variable as a time.Duration or panic if it is unset or
+// invalid.
+func (e *Env) MustGetDuration(key string) time.Duration {
+	result, err := parseDuration(key, e.MustGet(key))
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// Get an environment variable as an absolute URL (see GetURL) or panic if it
+// is unset or invalid.
+func MustGetURL(key string) *url.URL {
+	return std.MustGetURL(key)
+}
+
+// Get an environment variable as an absolute URL (see GetURL) or panic if it
+// is unset or invalid.
+func (e *Env) MustGetURL(key string) *url.URL {
+	result, err := parseURL(key, e.MustGet(key))
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
@@ -0,0 +1,226 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// A source whose variables are each unset after they are first looked up,
+// as if changed concurrently between reads.
+type onceSource map[string]string
+
+func (s onceSource) Lookup(key string) (string, bool) {
+	v, ok := s[key]
+	delete(s, key)
+	return v, ok
+}
+
+func TestGetOrReadsOnce(t *testing.T) {
+	e := New(nil)
+	e.SetSources(onceSource{
+		"INT":      "1",
+		"INT64":    "2",
+		"UINT":     "3",
+		"BOOL":     "true",
+		"DURATION": "4s",
+		"PARSED":   "5",
+	})
+
+	if got := e.GetIntOr("INT", -1); got != 1 {
+		t.Errorf("GetIntOr = %d, want 1", got)
+	}
+	if got := e.GetInt64Or("INT64", -1); got != 2 {
+		t.Errorf("GetInt64Or = %d, want 2", got)
+	}
+	if got := e.GetUintOr("UINT", 0); got != 3 {
+		t.Errorf("GetUintOr = %d, want 3", got)
+	}
+	if got := e.GetBoolOr("BOOL", false); !got {
+		t.Errorf("GetBoolOr = %t, want true", got)
+	}
+	if got := e.GetDurationOr("DURATION", 0); got != 4*time.Second {
+		t.Errorf("GetDurationOr = %s, want 4s", got)
+	}
+	if got := GetParsedOrFrom(e, "PARSED", func(s string) (string, error) { return s, nil }, "alt"); got != "5" {
+		t.Errorf("GetParsedOrFrom = %q, want %q", got, "5")
+	}
+}
+
+func TestGetOrAlternate(t *testing.T) {
+	e := New(map[string]string{"BAD": "x"})
+
+	for _, key := range []string{"MISSING", "BAD"} {
+		if got := e.GetIntOr(key, -1); got != -1 {
+			t.Errorf("GetIntOr(%s) = %d, want -1", key, got)
+		}
+		if got := e.GetUintOr(key, 7); got != 7 {
+			t.Errorf("GetUintOr(%s) = %d, want 7", key, got)
+		}
+		if got := e.GetBoolOr(key, true); !got {
+			t.Errorf("GetBoolOr(%s) = %t, want true", key, got)
+		}
+		if got := e.GetDurationOr(key, time.Second); got != time.Second {
+			t.Errorf("GetDurationOr(%s) = %s, want 1s", key, got)
+		}
+	}
+}
+
+func TestMustGetPanics(t *testing.T) {
+	e := New(map[string]string{
+		"INT":      "x",
+		"BOOL":     "maybe",
+		"DURATION": "soon",
+		"URL":      "example.com",
+	})
+
+	tests := []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"MustGetInt missing", func() { e.MustGetInt("MISSING") }, "env: missing required variable MISSING"},
+		{"MustGetInt bad", func() { e.MustGetInt("INT") }, `env: key INT value "x" is not a valid int: invalid syntax`},
+		{"MustGetBool missing", func() { e.MustGetBool("MISSING") }, "env: missing required variable MISSING"},
+		{"MustGetBool bad", func() { e.MustGetBool("BOOL") }, `env: key BOOL value "maybe" is not a valid bool`},
+		{"MustGetDuration missing", func() { e.MustGetDuration("MISSING") }, "env: missing required variable MISSING"},
+		{"MustGetDuration bad", func() { e.MustGetDuration("DURATION") }, `env: key DURATION value "soon" is not a valid duration`},
+		{"MustGetURL missing", func() { e.MustGetURL("MISSING") }, "env: missing required variable MISSING"},
+		{"MustGetURL bad", func() { e.MustGetURL("URL") }, `env: key URL value "example.com" is not a valid URL: missing scheme or host`},
+	}
+
+	for _, tt := range tests {
+		if got := panicked(tt.fn); got != tt.want {
+			t.Errorf("%s panicked with %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Call fn, returning the message of the value it panics with, if any.
+func panicked(fn func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+
+	fn()
+
+	return
+}
+
+func TestGetBytes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		err   bool
+	}{
+		{"512", 512, false},
+		{"10KB", 10000, false},
+		{"4MiB", 4 << 20, false},
+		{"1.5GB", 1500000000, false},
+		{"9007199254740993", 9007199254740993, false},
+		{"9223372036854775807", 9223372036854775807, false},
+		{"8191PiB", 8191 << 50, false},
+		{"9223372036854775808", 0, true},
+		{"8192PiB", 0, true},
+		{"-1", 0, true},
+		{"10XB", 0, true},
+		{"1.5", 0, true},
+		{"0.5B", 0, true},
+		{"1.0001KB", 0, true},
+		{"1.1KB", 1100, false},
+		{"1e3", 1000, false},
+		{"0.5KiB", 512, false},
+	}
+
+	for _, tt := range tests {
+		got, err := New(map[string]string{"SIZE": tt.value}).GetBytes("SIZE")
+		if (err != nil) != tt.err {
+			t.Errorf("GetBytes(%q) error = %v, want error %t", tt.value, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("GetBytes(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetStringMap(t *testing.T) {
+	e := New(map[string]string{
+		"OBJECT": `{"a":"b"}`,
+		"NULL":   "null",
+		"ARRAY":  `["a"]`,
+	})
+
+	if m, err := e.GetStringMap("OBJECT"); err != nil || len(m) != 1 || m["a"] != "b" {
+		t.Errorf("GetStringMap(OBJECT) = %q, %v, want map[a:b]", m, err)
+	}
+	if m, err := e.GetStringMap("MISSING"); err != nil || m == nil || len(m) != 0 {
+		t.Errorf("GetStringMap(MISSING) = %#v, %v, want an empty map", m, err)
+	}
+
+	for _, key := range []string{"NULL", "ARRAY"} {
+		m, err := e.GetStringMap(key)
+		var perr *ParseError
+		if !errors.As(err, &perr) || m != nil {
+			t.Errorf("GetStringMap(%s) = %q, %v, want a *ParseError", key, m, err)
+		}
+	}
+}
+
+func TestGetDurationSliceError(t *testing.T) {
+	e := New(map[string]string{"D": "1s,soon"})
+
+	_, err := e.GetDurationSlice("D")
+	want := `env: key D value "1s,soon" is not a valid duration list: element 1 "soon": time: invalid duration "soon"`
+	if err == nil || err.Error() != want {
+		t.Errorf("GetDurationSlice error = %v, want %s", err, want)
+	}
+}
+
+func TestGetFloatOrErrBlank(t *testing.T) {
+	e := New(map[string]string{"F": "   "})
+
+	if got, err := e.GetFloatOrErr("F", 1.5); got != 1.5 || err != nil {
+		t.Errorf("GetFloatOrErr = %v, %v, want 1.5, nil", got, err)
+	}
+}
+
+func TestMustGetURLReadsOnce(t *testing.T) {
+	e := New(nil)
+	e.SetSources(onceSource{"URL": "https://example.com/db"})
+
+	if u := e.MustGetURL("URL"); u == nil || u.Host != "example.com" {
+		t.Errorf("MustGetURL = %v, want https://example.com/db", u)
+	}
+}
+
+func TestGetPercent(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+		err   string
+	}{
+		{"25%", 0.25, ""},
+		{"0.25", 0.25, ""},
+		{" 100 % ", 1, ""},
+		{"0", 0, ""},
+		{"25", 0, `env: key P value "25" is not a valid percentage: must be a fraction from 0 to 1, or a percentage such as "25%"`},
+		{"250", 0, `env: key P value "250" is not a valid percentage: must be a fraction from 0 to 1`},
+		{"-0.5", 0, `env: key P value "-0.5" is not a valid percentage: must be a fraction from 0 to 1`},
+		{"150%", 0, `env: key P value "150%" is not a valid percentage: must be from 0% to 100%`},
+	}
+
+	for _, tt := range tests {
+		got, err := New(map[string]string{"P": tt.value}).GetPercent("P")
+		if tt.err == "" && err != nil {
+			t.Errorf("GetPercent(%q): %v", tt.value, err)
+		} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("GetPercent(%q) error = %v, want %s", tt.value, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("GetPercent(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Populate the fields of the struct pointed to by v from environment
+// variables named by their `env:"KEY"` tags. Fields may be of type string,
+// int, bool, float64 or time.Duration and are parsed as by the matching Get
+// function. Fields without an env tag are left alone.
+//
+// A `default:"..."` tag supplies a value to use when the variable is unset,
+// and a `required:"true"` tag makes an unset variable (with no default) an
+// error. Fields whose variables are unset otherwise keep their value. The
+// returned error lists every field that could not be populated.
+//
+// Fields may also be validated once populated. A `min:"1"` or `max:"65535"`
+// tag bounds an int or float64 field, and a `oneof:"dev,prod"` tag limits a
+// string field to one of a comma-separated list of values. These tags are an
+// error on fields of other types, including time.Duration. Each violation is
+// listed in the returned error along with the field, tag and value.
+//
+// Fields holding nested structs are populated recursively, see
+// UnmarshalPrefix.
+func Unmarshal(v interface{}) error {
+	return std.Unmarshal(v)
+}
+
+// Populate a struct from tagged fields, see Unmarshal.
+func (e *Env) Unmarshal(v interface{}) error {
+	return e.UnmarshalPrefix(EmptyString, v)
+}
+
+// Populate a struct as Unmarshal does, reading each tagged field from the
+// variable named by prefix followed by its tag, so `env:"HOST"` with prefix
+// "MYAPP_" reads MYAPP_HOST.
+//
+// Nested struct fields extend the prefix with their env tag (or upper-cased
+// field name if untagged) and an underscore separator, so a field `DB` holding
+// a struct with a field tagged `env:"PORT"` reads MYAPP_DB_PORT. Embedded
+// structs without a tag share the prefix of the struct that embeds them.
+func UnmarshalPrefix(prefix string, v interface{}) error {
+	return std.UnmarshalPrefix(prefix, v)
+}
+
+// Populate a struct from tagged fields under a prefix, see UnmarshalPrefix.
+func (e *Env) UnmarshalPrefix(prefix string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: Unmarshal requires a non-nil struct pointer, got %T", v)
+	}
+
+	return errors.Join(e.unmarshal(prefix, rv.Elem())...)
+}
+
+// Populate the tagged fields of a struct value, returning an error for each
+// field that failed.
+func (e *Env) unmarshal(prefix string, rv reflect.Value) (errs []error) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		key, ok := f.Tag.Lookup("env")
+
+		if nested(f.Type) {
+			switch {
+			case ok && key != EmptyString:
+				errs = append(errs, e.unmarshal(prefix+key+"_", rv.Field(i))...)
+			case f.Anonymous:
+				errs = append(errs, e.unmarshal(prefix, rv.Field(i))...)
+			default:
+				errs = append(errs, e.unmarshal(prefix+strings.ToUpper(f.Name)+"_", rv.Field(i))...)
+			}
+			continue
+		}
+
+		if !ok || key == EmptyString || !f.IsExported() {
+			continue
+		}
+
+		key = prefix + key
+		v := e.Get(key)
+		if v == EmptyString {
+			if def, ok := f.Tag.Lookup("default"); ok {
+				v = def
+			} else if f.Tag.Get("required") == "true" {
+				errs = append(errs, fmt.Errorf("%w (field %s)", &MissingError{Key: key}, f.Name))
+				continue
+			} else {
+				continue
+			}
+		}
+
+		if err := setField(rv.Field(i), key, v); err != nil {
+			errs = append(errs, fmt.Errorf("%w (field %s)", err, f.Name))
+			continue
+		}
+
+		for _, err := range validate(f, rv.Field(i), key, v) {
+			errs = append(errs, fmt.Errorf("%w (field %s)", err, f.Name))
+		}
+	}
+
+	return
+}
+
+// Determine if a field type is a struct of configuration to populate
+// recursively, rather than a value type such as time.Time.
+func nested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// Parse the value of key into a struct field according to its type.
+func setField(fv reflect.Value, key, v string) (err error) {
+	switch {
+	case fv.Type() == durationType:
+		var d time.Duration
+		if d, err = parseDuration(key, v); err == nil {
+			fv.SetInt(int64(d))
+		}
+	case fv.Kind() == reflect.String:
+		fv.SetString(v)
+	case fv.Kind() == reflect.Int:
+		var n int
+		if n, err = parseInt(key, v); err == nil {
+			fv.SetInt(int64(n))
+		}
+	case fv.Kind() == reflect.Bool:
+		var b bool
+		if b, err = parseBool(key, v); err == nil {
+			fv.SetBool(b)
+		}
+	case fv.Kind() == reflect.Float64:
+		var n float64
+		if n, err = parseFloat(key, v); err == nil {
+			fv.SetFloat(n)
+		}
+	default:
+		err = fmt.Errorf("env: key %s has unsupported type %s", key, fv.Type())
+	}
+
+	return
+}
+
+// Check a populated field against its min, max and oneof tags, returning an
+// error for each that is violated.
+func validate(f reflect.StructField, fv reflect.Value, key, v string) (errs []error) {
+	var n float64
+	numeric := true
+	switch fv.Kind() {
+	case reflect.Int:
+		n = float64(fv.Int())
+	case reflect.Float64:
+		n = fv.Float()
+	default:
+		numeric = false
+	}
+
+	for _, tag := range []string{"min", "max"} {
+		bound, ok := f.Tag.Lookup(tag)
+		if !ok {
+			continue
+		} else if !numeric {
+			errs = append(errs, fmt.Errorf("env: key %s has %s tag on unsupported type %s", key, tag, fv.Type()))
+			continue
+		}
+
+		limit, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env: key %s has invalid %s tag %q", key, tag, bound))
+		} else if (tag == "min" && n < limit) || (tag == "max" && n > limit) {
+			errs = append(errs, fmt.Errorf("env: key %s value %q violates %s:%q", key, v, tag, bound))
+		}
+	}
+
+	allowed, ok := f.Tag.Lookup("oneof")
+	if ok && fv.Kind() != reflect.String {
+		errs = append(errs, fmt.Errorf("env: key %s has oneof tag on unsupported type %s", key, fv.Type()))
+	} else if ok {
+		found := false
+		for _, a := range strings.Split(allowed, ",") {
+			if fv.String() == strings.TrimSpace(a) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			errs = append(errs, fmt.Errorf("env: key %s value %q violates oneof:%q", key, v, allowed))
+		}
+	}
+
+	return
+}
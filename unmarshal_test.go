@@ -0,0 +1,93 @@
+package env
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalNested(t *testing.T) {
+	e := New(map[string]string{"APP_DB_HOST": "db", "APP_DB_PORT": "5432", "APP_NAME": "x"})
+
+	var c struct {
+		Name string `env:"NAME"`
+		DB   struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		}
+	}
+	if err := e.UnmarshalPrefix("APP_", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Name != "x" || c.DB.Host != "db" || c.DB.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+}
+
+func TestUnmarshalTimeUnsupported(t *testing.T) {
+	e := New(map[string]string{"START": "2024-01-02T03:04:05Z"})
+
+	var c struct {
+		Start time.Time `env:"START"`
+		Other time.Time
+	}
+	err := e.Unmarshal(&c)
+	if err == nil || !strings.Contains(err.Error(), "unsupported type time.Time") {
+		t.Fatalf("got %v, want unsupported type error", err)
+	}
+}
+
+func TestUnmarshalValidate(t *testing.T) {
+	type config struct {
+		Port    int     `env:"PORT" min:"1" max:"65535"`
+		Ratio   float64 `env:"RATIO" min:"0" max:"1"`
+		Mode    string  `env:"MODE" oneof:"dev, prod"`
+		Workers int     `env:"WORKERS" min:"one"`
+	}
+
+	tests := []struct {
+		vals map[string]string
+		want []string
+	}{
+		{map[string]string{"PORT": "1", "RATIO": "1", "MODE": "prod"}, nil},
+		{map[string]string{"PORT": "65535", "RATIO": "0", "MODE": "dev"}, nil},
+		{map[string]string{"PORT": "0"}, []string{`env: key PORT value "0" violates min:"1" (field Port)`}},
+		{map[string]string{"PORT": "65536"}, []string{`env: key PORT value "65536" violates max:"65535" (field Port)`}},
+		{map[string]string{"RATIO": "1.5"}, []string{`env: key RATIO value "1.5" violates max:"1" (field Ratio)`}},
+		{map[string]string{"MODE": "test"}, []string{`env: key MODE value "test" violates oneof:"dev, prod" (field Mode)`}},
+		{map[string]string{"WORKERS": "2"}, []string{`env: key WORKERS has invalid min tag "one" (field Workers)`}},
+		{map[string]string{"PORT": "0", "RATIO": "-1", "MODE": "test"}, []string{
+			`env: key PORT value "0" violates min:"1" (field Port)`,
+			`env: key RATIO value "-1" violates min:"0" (field Ratio)`,
+			`env: key MODE value "test" violates oneof:"dev, prod" (field Mode)`,
+		}},
+	}
+
+	for _, tt := range tests {
+		var c config
+		var got string
+		if err := New(tt.vals).Unmarshal(&c); err != nil {
+			got = err.Error()
+		}
+		if want := strings.Join(tt.want, "\n"); got != want {
+			t.Errorf("Unmarshal(%v) error = %q, want %q", tt.vals, got, want)
+		}
+	}
+}
+
+func TestUnmarshalValidateUnsupported(t *testing.T) {
+	e := New(map[string]string{"TIMEOUT": "5s", "DEBUG": "true"})
+
+	var c struct {
+		Timeout time.Duration `env:"TIMEOUT" min:"1s"`
+		Debug   bool          `env:"DEBUG" oneof:"true"`
+	}
+	err := e.Unmarshal(&c)
+
+	want := "env: key TIMEOUT has min tag on unsupported type time.Duration (field Timeout)\n" +
+		"env: key DEBUG has oneof tag on unsupported type bool (field Debug)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Unmarshal error = %v, want %s", err, want)
+	}
+}
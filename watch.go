@@ -0,0 +1,144 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Reload environment variables from a given filename with LoadFile whenever
+// one of the given signals (SIGHUP if none) is received, until stop is
+// called. Returns an error if the file does not exist when called. Errors
+// reloading the file, such as if it has since been removed, are logged and
+// the previously loaded values are kept.
+func Watch(name string, signals ...os.Signal) (stop func(), err error) {
+	return std.Watch(name, signals...)
+}
+
+// Reload environment variables from a file on a signal, see Watch.
+func (e *Env) Watch(name string, signals ...os.Signal) (stop func(), err error) {
+	if _, err = os.Stat(name); err != nil {
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	e.WatchContext(ctx, name, func(err error) {
+		if err != nil {
+			log.Printf("env: reloading %s: %v", name, err)
+		}
+	}, signals...)
+
+	return
+}
+
+// Reload environment variables from a given filename with LoadFile whenever
+// one of the given signals (SIGHUP if none) is received, until ctx is
+// canceled. The onReload callback, if not nil, receives the result of each
+// reload attempt.
+func WatchContext(ctx context.Context, name string, onReload func(error), signals ...os.Signal) {
+	std.WatchContext(ctx, name, onReload, signals...)
+}
+
+// Reload environment variables from a file on a signal until ctx is canceled,
+// see WatchContext.
+func (e *Env) WatchContext(ctx context.Context, name string, onReload func(error), signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ch:
+				err := e.LoadFile(name)
+				if onReload != nil {
+					onReload(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Reload environment variables from a given filename with LoadFile whenever
+// its modification time changes, checking every interval until stop is
+// called. The onReload callback, if not nil, receives the result of each
+// reload attempt, or an error if the file can no longer be read. An interval
+// that is not positive is reported to onReload and nothing is watched.
+func WatchFile(name string, interval time.Duration, onReload func(error)) (stop func()) {
+	return std.WatchFile(name, interval, onReload)
+}
+
+// Reload environment variables from a file when it changes, see WatchFile.
+func (e *Env) WatchFile(name string, interval time.Duration, onReload func(error)) (stop func()) {
+	ctx, stop := context.WithCancel(context.Background())
+	e.WatchFileContext(ctx, name, interval, onReload)
+
+	return
+}
+
+// Reload environment variables from a given filename with LoadFile whenever
+// its modification time changes, checking every interval until ctx is
+// canceled. Changes and errors are reported to onReload as WatchFile does.
+func WatchFileContext(ctx context.Context, name string, interval time.Duration, onReload func(error)) {
+	std.WatchFileContext(ctx, name, interval, onReload)
+}
+
+// Reload environment variables from a file when it changes until ctx is
+// canceled, see WatchFileContext.
+func (e *Env) WatchFileContext(ctx context.Context, name string, interval time.Duration, onReload func(error)) {
+	report := func(err error) {
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+
+	if interval <= 0 {
+		report(fmt.Errorf("env: watch interval %s is not positive", interval))
+		return
+	}
+
+	var last time.Time
+	var failing bool
+	if info, err := os.Stat(name); err == nil {
+		last = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(name)
+				if err != nil {
+					if !failing {
+						failing = true
+						report(err)
+					}
+					continue
+				}
+
+				if failing || !info.ModTime().Equal(last) {
+					failing = false
+					last = info.ModTime()
+					report(e.LoadFile(name))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,52 @@
+package env
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatchFileInterval(t *testing.T) {
+	name := writeFile(t, ".env", "A=1\n")
+
+	var got error
+	stop := New(nil).WatchFile(name, 0, func(err error) { got = err })
+	stop()
+
+	if want := "env: watch interval 0s is not positive"; got == nil || got.Error() != want {
+		t.Errorf("onReload error = %v, want %s", got, want)
+	}
+}
+
+func TestWatchFileContext(t *testing.T) {
+	name := writeFile(t, ".env", "A=1\n")
+
+	e := New(nil)
+	reloaded := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.WatchFileContext(ctx, name, 5*time.Millisecond, func(err error) { reloaded <- err })
+
+	// Move the modification time forward so the change is seen even on
+	// filesystems with coarse timestamps.
+	if err := os.WriteFile(name, []byte("A=2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("file not reloaded")
+	}
+	if got := e.Get("A"); got != "2" {
+		t.Errorf("A = %q, want 2", got)
+	}
+}